@@ -7,6 +7,7 @@ import (
 
 	"github.com/chiyiangel/media-organizer-v2/internal/config"
 	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
+	"github.com/chiyiangel/media-organizer-v2/internal/pathtemplate"
 )
 
 // CLIParser handles command line argument parsing
@@ -35,11 +36,29 @@ func (p *CLIParser) setupFlags() {
 	p.flags.StringVar(&p.config.SourceDir, "source", "", i18n.T("cli.option.source"))
 	p.flags.StringVar(&p.config.TargetDir, "target", "", i18n.T("cli.option.target"))
 	p.flags.StringVar((*string)(&p.config.DuplicateDetection), "detection", "", i18n.T("cli.option.detection"))
+	p.flags.StringVar(&p.config.HashAlgorithm, "hash", "", i18n.T("cli.option.hash"))
 	p.flags.StringVar((*string)(&p.config.DuplicateStrategy), "strategy", "", i18n.T("cli.option.strategy"))
+	p.flags.StringVar((*string)(&p.config.TransferMode), "transfer", "", i18n.T("cli.option.transfer"))
+	p.flags.BoolVar(&p.config.DryRun, "dry-run", false, i18n.T("cli.option.dry_run"))
+	p.flags.BoolVar(&p.config.PreserveTimes, "preserve-times", false, i18n.T("cli.option.preserve_times"))
+	p.flags.BoolVar(&p.config.PreserveMode, "preserve-mode", false, i18n.T("cli.option.preserve_mode"))
+	p.flags.BoolVar(&p.config.PreserveXattrs, "preserve-xattrs", false, i18n.T("cli.option.preserve_xattrs"))
+	p.flags.BoolVar(&p.config.MtimeFromDate, "mtime-from-date", false, i18n.T("cli.option.mtime_from_date"))
+	p.flags.BoolVar(&p.config.Verify, "verify", false, i18n.T("cli.option.verify"))
+	p.flags.IntVar(&p.config.Workers, "workers", 0, i18n.T("cli.option.workers"))
+	p.flags.IntVar(&p.config.PerceptualThreshold, "similarity", 0, i18n.T("cli.option.similarity"))
+	p.flags.BoolVar(&p.config.KeepHigherResolution, "keep-higher-res", false, i18n.T("cli.option.keep_higher"))
+	p.flags.StringVar(&p.config.PathTemplate, "template", "", i18n.T("cli.option.template"))
+	p.flags.StringVar(&p.config.RenamePattern, "rename", "", i18n.T("cli.option.rename"))
+	p.flags.StringVar(&p.config.PhotoTargetDir, "photo-target", "", i18n.T("cli.option.photo_target"))
+	p.flags.StringVar(&p.config.VideoTargetDir, "video-target", "", i18n.T("cli.option.video_target"))
+	p.flags.StringVar(&p.config.PhotoPathTemplate, "photo-template", "", i18n.T("cli.option.photo_template"))
+	p.flags.StringVar(&p.config.VideoPathTemplate, "video-template", "", i18n.T("cli.option.video_template"))
 
 	// Silent mode and configuration flags
 	p.flags.StringVar((*string)(&p.config.Mode), "mode", "", i18n.T("cli.option.mode"))
 	p.flags.BoolVar(&p.silentFlag, "silent", false, i18n.T("cli.option.silent"))
+	p.flags.BoolVar(&p.config.Resume, "resume", false, i18n.T("cli.option.resume"))
 	p.flags.StringVar(&p.config.ConfigFile, "config", "", i18n.T("cli.option.config"))
 	p.flags.StringVar(&p.config.LogLevel, "log-level", "", i18n.T("cli.option.log_level"))
 
@@ -87,13 +106,17 @@ func (p *CLIParser) validate() error {
 	}
 
 	// Validate duplicate detection if specified
-	if p.config.DuplicateDetection != "" &&
-		p.config.DuplicateDetection != config.DetectionFilename &&
-		p.config.DuplicateDetection != config.DetectionMD5 {
+	if p.config.DuplicateDetection != "" && !p.config.DuplicateDetection.Valid() {
 		errorMsg := i18n.Tf("cli.error.invalid_detection", p.config.DuplicateDetection)
 		return fmt.Errorf("%s", errorMsg)
 	}
 
+	// Validate hash algorithm if specified
+	if p.config.HashAlgorithm != "" && !config.ValidHashAlgorithm(p.config.HashAlgorithm) {
+		errorMsg := i18n.Tf("cli.error.invalid_hash", p.config.HashAlgorithm)
+		return fmt.Errorf("%s", errorMsg)
+	}
+
 	// Validate duplicate strategy if specified
 	if p.config.DuplicateStrategy != "" &&
 		p.config.DuplicateStrategy != config.StrategySkip &&
@@ -103,6 +126,31 @@ func (p *CLIParser) validate() error {
 		return fmt.Errorf("%s", errorMsg)
 	}
 
+	// Validate transfer mode if specified
+	if p.config.TransferMode != "" && !p.config.TransferMode.Valid() {
+		errorMsg := i18n.Tf("cli.error.invalid_transfer", p.config.TransferMode)
+		return fmt.Errorf("%s", errorMsg)
+	}
+
+	// Validate path templates if specified
+	for _, layout := range []string{p.config.PathTemplate, p.config.PhotoPathTemplate, p.config.VideoPathTemplate} {
+		if layout == "" {
+			continue
+		}
+		if _, err := pathtemplate.Parse(layout); err != nil {
+			errorMsg := i18n.Tf("cli.error.invalid_template", err)
+			return fmt.Errorf("%s", errorMsg)
+		}
+	}
+
+	// Validate rename pattern if specified
+	if p.config.RenamePattern != "" {
+		if _, err := pathtemplate.ParseName(p.config.RenamePattern); err != nil {
+			errorMsg := i18n.Tf("cli.error.invalid_rename", err)
+			return fmt.Errorf("%s", errorMsg)
+		}
+	}
+
 	// Validate log level if specified
 	if p.config.LogLevel != "" {
 		validLogLevels := map[string]bool{
@@ -126,15 +174,39 @@ func (p *CLIParser) ShowHelp() {
 	fmt.Print(titleMsg)
 	fmt.Println(i18n.T("cli.help.usage"))
 	fmt.Println()
+	fmt.Println(i18n.T("cli.commands"))
+	fmt.Println("  plan                " + i18n.T("cli.command.plan"))
+	fmt.Println("  apply               " + i18n.T("cli.command.apply"))
+	fmt.Println("  undo                " + i18n.T("cli.command.undo"))
+	fmt.Println("  -out string         " + i18n.T("cli.option.plan_out"))
+	fmt.Println()
 	fmt.Println(i18n.T("cli.options.core"))
 	fmt.Println("  -source string      " + i18n.T("cli.option.source"))
 	fmt.Println("  -target string      " + i18n.T("cli.option.target"))
 	fmt.Println("  -detection string   " + i18n.T("cli.option.detection"))
+	fmt.Println("  -hash string        " + i18n.T("cli.option.hash"))
 	fmt.Println("  -strategy string    " + i18n.T("cli.option.strategy"))
+	fmt.Println("  -transfer string    " + i18n.T("cli.option.transfer"))
+	fmt.Println("  -dry-run            " + i18n.T("cli.option.dry_run"))
+	fmt.Println("  -preserve-times     " + i18n.T("cli.option.preserve_times"))
+	fmt.Println("  -preserve-mode      " + i18n.T("cli.option.preserve_mode"))
+	fmt.Println("  -preserve-xattrs    " + i18n.T("cli.option.preserve_xattrs"))
+	fmt.Println("  -mtime-from-date    " + i18n.T("cli.option.mtime_from_date"))
+	fmt.Println("  -verify             " + i18n.T("cli.option.verify"))
+	fmt.Println("  -workers int        " + i18n.T("cli.option.workers"))
+	fmt.Println("  -similarity int     " + i18n.T("cli.option.similarity"))
+	fmt.Println("  -keep-higher-res    " + i18n.T("cli.option.keep_higher"))
+	fmt.Println("  -template string    " + i18n.T("cli.option.template"))
+	fmt.Println("  -rename string      " + i18n.T("cli.option.rename"))
+	fmt.Println("  -photo-target string    " + i18n.T("cli.option.photo_target"))
+	fmt.Println("  -video-target string    " + i18n.T("cli.option.video_target"))
+	fmt.Println("  -photo-template string  " + i18n.T("cli.option.photo_template"))
+	fmt.Println("  -video-template string  " + i18n.T("cli.option.video_template"))
 	fmt.Println()
 	fmt.Println(i18n.T("cli.options.silent"))
 	fmt.Println("  -mode string        " + i18n.T("cli.option.mode"))
 	fmt.Println("  -silent             " + i18n.T("cli.option.silent"))
+	fmt.Println("  -resume             " + i18n.T("cli.option.resume"))
 	fmt.Println("  -config string      " + i18n.T("cli.option.config"))
 	fmt.Println("  -log-level string   " + i18n.T("cli.option.log_level"))
 	fmt.Println()
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/app"
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
+)
+
+// runCommand dispatches the plan/apply/undo subcommands, returning false for normal runs
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "plan":
+		runPlanCommand(args[1:])
+	case "apply":
+		runApplyCommand(args[1:])
+	case "undo":
+		runUndoCommand(args[1:])
+	default:
+		return false
+	}
+	return true
+}
+
+// runPlanCommand plans every file with the regular options and writes the plan file
+func runPlanCommand(args []string) {
+	parser := NewCLIParser()
+	out := parser.flags.String("out", "plan.json", i18n.T("cli.option.plan_out"))
+
+	cliConfig, err := parser.Parse(args)
+	if err != nil {
+		exitWithError(i18n.Tf("cli.error.cli_parse", err))
+	}
+
+	finalConfig, err := config.LoadFullConfig(cliConfig)
+	if err != nil {
+		exitWithError(i18n.Tf("cli.error.config_load", err))
+	}
+
+	// Planning is always non-interactive
+	finalConfig.Mode = config.ModeSilent
+	if err := finalConfig.Validate(); err != nil {
+		exitWithError(i18n.Tf("cli.error.config_validate", err))
+	}
+
+	runner, err := app.NewPlanRunner(finalConfig)
+	if err != nil {
+		exitWithError(i18n.Tf("cli.error.plan", err))
+	}
+	if err := runner.Plan(*out); err != nil {
+		exitWithError(i18n.Tf("cli.error.plan", err))
+	}
+}
+
+// runApplyCommand applies a saved plan file with the regular options (verify, preserve-*, config file)
+func runApplyCommand(args []string) {
+	parser := NewCLIParser()
+	cliConfig, err := parser.Parse(args)
+	if err != nil {
+		exitWithError(i18n.Tf("cli.error.cli_parse", err))
+	}
+	if parser.flags.NArg() != 1 {
+		exitWithError(i18n.T("cli.error.apply_usage"))
+	}
+
+	finalConfig, err := config.LoadFullConfig(cliConfig)
+	if err != nil {
+		exitWithError(i18n.Tf("cli.error.config_load", err))
+	}
+
+	// Applying is always non-interactive; the plan fills in missing directories before validation
+	finalConfig.Mode = config.ModeSilent
+
+	runner, err := app.NewPlanRunner(finalConfig)
+	if err != nil {
+		exitWithError(i18n.Tf("cli.error.apply", err))
+	}
+	if err := runner.Apply(parser.flags.Arg(0)); err != nil {
+		exitWithError(i18n.Tf("cli.error.apply", err))
+	}
+}
+
+// runUndoCommand reverts a previous run from its journal
+func runUndoCommand(args []string) {
+	flags := flag.NewFlagSet("undo", flag.ExitOnError)
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		exitWithError(i18n.T("cli.error.undo_usage"))
+	}
+
+	runner, err := app.NewUndoRunner()
+	if err != nil {
+		exitWithError(i18n.Tf("cli.error.undo", err))
+	}
+	if err := runner.Undo(flags.Arg(0)); err != nil {
+		exitWithError(i18n.Tf("cli.error.undo", err))
+	}
+}
+
+// exitWithError prints the message and exits with status 1
+func exitWithError(message string) {
+	fmt.Print(message + "\n")
+	os.Exit(1)
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	// 内置时区数据库，没有安装时区数据的系统（例如 Windows）也能使用 folderTimezone
+	_ "time/tzdata"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/chiyiangel/media-organizer-v2/internal/app"
 	"github.com/chiyiangel/media-organizer-v2/internal/config"
@@ -12,6 +15,11 @@ import (
 )
 
 func main() {
+	// Handle plan/apply subcommands
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
 	// Parse CLI arguments first
 	cliConfig, err := ParseCLI()
 	if err != nil {
@@ -70,7 +78,7 @@ func runInteractiveMode(config *config.Config) {
 	_ = i18n.GetLocalizer() // 这会触发语言检测
 
 	// 创建模型
-	m := ui.NewModel()
+	m := ui.NewModel(config)
 
 	// 创建程序
 	p := tea.NewProgram(m, tea.WithAltScreen())
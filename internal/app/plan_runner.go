@@ -0,0 +1,177 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
+	"github.com/chiyiangel/media-organizer-v2/internal/logger"
+	"github.com/chiyiangel/media-organizer-v2/internal/organizer"
+)
+
+// PlanRunner handles the two-phase plan/apply workflow
+type PlanRunner struct {
+	config *config.Config
+	logger *logger.Logger
+}
+
+// NewPlanRunner creates a new plan/apply runner
+func NewPlanRunner(config *config.Config) (*PlanRunner, error) {
+	log, err := logger.NewLogger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+
+	return &PlanRunner{
+		config: config,
+		logger: log,
+	}, nil
+}
+
+// Plan scans the source directory, plans every file in memory and saves the plan
+func (r *PlanRunner) Plan(path string) error {
+	defer r.logger.Close()
+
+	// Planning never touches the filesystem
+	cfg := *r.config
+	cfg.DryRun = true
+
+	// An interrupted plan is incomplete, so nothing is saved
+	ctx, stop := handleInterrupt()
+	defer stop()
+	start := time.Now()
+
+	files, err := organizer.NewScanner(cfg.SourceDir).Scan(ctx)
+	if ctx.Err() != nil {
+		fmt.Println(i18n.Tf("silent.cancelled", time.Since(start)))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s", i18n.Tf("silent.scan_failed", err.Error()))
+	}
+
+	processor := organizer.NewProcessor(&cfg)
+	if _, err := processor.DedupeSource(ctx, files); err != nil {
+		fmt.Println(i18n.Tf("silent.cancelled", time.Since(start)))
+		return nil
+	}
+
+	var records []organizer.ProcessRecord
+	failed := 0
+	for result := range processor.ProcessAll(ctx, files, cfg.Workers) {
+		record := result.Record
+		if record == nil {
+			continue
+		}
+		r.logger.LogRecord(record)
+		if record.Result == organizer.ResultFailed {
+			failed++
+		}
+		records = append(records, *record)
+	}
+
+	plan, err := organizer.NewPlan(ctx, cfg.SourceDir, records, processor.Hasher())
+	if ctx.Err() != nil {
+		fmt.Println(i18n.Tf("silent.cancelled", time.Since(start)))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// Apply uses the same target roots for backups and temp-file cleanup
+	plan.PhotoTargetDir = cfg.TargetDirFor(string(organizer.FileTypePhoto))
+	plan.VideoTargetDir = cfg.TargetDirFor(string(organizer.FileTypeVideo))
+	if err := plan.Save(path); err != nil {
+		return err
+	}
+
+	skipped := 0
+	for _, entry := range plan.Entries {
+		if entry.Action == organizer.ActionSkip {
+			skipped++
+		}
+	}
+	fmt.Println(i18n.Tf("plan.summary", len(plan.Entries), len(plan.Entries)-skipped, skipped, failed))
+	fmt.Println(i18n.Tf("plan.saved", path))
+	fmt.Println(i18n.Tf("silent.log_saved", r.logger.GetPath()))
+	return nil
+}
+
+// Apply executes exactly the entries of a saved plan
+func (r *PlanRunner) Apply(path string) error {
+	defer r.logger.Close()
+
+	plan, err := organizer.LoadPlan(path)
+	if err != nil {
+		return err
+	}
+	// Options come from the flags and config file; directories not given there come from the plan
+	cfg := *r.config
+	if cfg.SourceDir == "" {
+		cfg.SourceDir = plan.SourceDir
+	}
+	if cfg.TargetDirFor(string(organizer.FileTypePhoto)) == "" {
+		cfg.PhotoTargetDir = plan.PhotoTargetDir
+	}
+	if cfg.TargetDirFor(string(organizer.FileTypeVideo)) == "" {
+		cfg.VideoTargetDir = plan.VideoTargetDir
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	fmt.Println(i18n.Tf("plan.apply_start", path, len(plan.Entries)))
+
+	processor := organizer.NewProcessor(&cfg)
+	ctx, stop := handleInterrupt()
+	defer stop()
+
+	// Remove temporary files left behind by an interrupted run
+	cleanupTempFiles(processor, r.logger)
+	journal, err := organizer.NewJournal()
+	if err != nil {
+		return fmt.Errorf("failed to create journal: %w", err)
+	}
+	processor.SetJournal(journal)
+
+	stats := &organizer.Statistics{
+		TotalFiles: len(plan.Entries),
+		StartTime:  time.Now(),
+	}
+	for _, entry := range plan.Entries {
+		record := processor.Apply(ctx, entry)
+		if record == nil {
+			// Cancelled; the partial target has been removed
+			break
+		}
+		r.logger.LogRecord(record)
+		stats.ProcessedFiles++
+
+		switch record.Result {
+		case organizer.ResultSkipped:
+			stats.SkippedCount++
+		case organizer.ResultFailed:
+			stats.FailedCount++
+			fmt.Println(i18n.Tf("plan.apply_failed", entry.Source, record.Message))
+		}
+	}
+
+	stats.EndTime = time.Now()
+	stats.Duration = stats.EndTime.Sub(stats.StartTime)
+	stats.Cancelled = ctx.Err() != nil
+
+	fmt.Println(i18n.Tf("plan.apply_summary",
+		stats.ProcessedFiles-stats.SkippedCount-stats.FailedCount, stats.SkippedCount, stats.FailedCount))
+	if stats.Cancelled {
+		fmt.Println(i18n.Tf("silent.unprocessed_count", stats.TotalFiles-stats.ProcessedFiles))
+		fmt.Println(i18n.Tf("silent.cancelled", stats.Duration))
+	}
+	fmt.Println(i18n.Tf("silent.log_saved", r.logger.GetPath()))
+	closeJournal(journal, r.logger)
+	r.logger.LogStatistics(stats)
+
+	if stats.FailedCount > 0 {
+		fmt.Println(i18n.T("silent.failed_notice"))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -15,9 +16,10 @@ import (
 
 // SilentRunner handles non-interactive execution of media organization
 type SilentRunner struct {
-	config    *config.Config
-	logger    *logger.Logger
-	processor *organizer.Processor
+	config     *config.Config
+	logger     *logger.Logger
+	processor  *organizer.Processor
+	checkpoint *organizer.Checkpoint
 }
 
 // NewSilentRunner creates a new silent mode runner
@@ -42,13 +44,31 @@ func NewSilentRunner(config *config.Config) (*SilentRunner, error) {
 func (r *SilentRunner) Run() error {
 	fmt.Println(i18n.T("silent.start"))
 	fmt.Println(i18n.Tf("silent.source_dir", r.config.SourceDir))
-	fmt.Println(i18n.Tf("silent.target_dir", r.config.TargetDir))
+	if r.config.PhotoTargetDir != "" || r.config.VideoTargetDir != "" {
+		fmt.Println(i18n.Tf("silent.photo_target_dir", r.config.TargetDirFor(string(organizer.FileTypePhoto))))
+		fmt.Println(i18n.Tf("silent.video_target_dir", r.config.TargetDirFor(string(organizer.FileTypeVideo))))
+	} else {
+		fmt.Println(i18n.Tf("silent.target_dir", r.config.TargetDir))
+	}
 	fmt.Println(i18n.Tf("silent.duplicate_detection", r.config.DuplicateDetection))
+	fmt.Println(i18n.Tf("silent.hash_algorithm", r.config.DigestAlgorithm()))
 	fmt.Println(i18n.Tf("silent.duplicate_strategy", r.config.DuplicateStrategy))
+	if r.config.TransferMode != "" {
+		fmt.Println(i18n.Tf("silent.transfer_mode", r.config.TransferMode))
+	}
+	if r.config.DryRun {
+		fmt.Println(i18n.T("silent.dry_run"))
+	}
 	fmt.Println()
 
 	// Set up interrupt handling
-	r.handleInterrupt()
+	ctx, stop := handleInterrupt()
+	defer stop()
+
+	// Remove temporary files left behind by an interrupted run
+	if !r.config.DryRun {
+		cleanupTempFiles(r.processor, r.logger)
+	}
 
 	// Start processing
 	startTime := time.Now()
@@ -56,7 +76,10 @@ func (r *SilentRunner) Run() error {
 
 	// Create scanner and scan files
 	scanner := organizer.NewScanner(r.config.SourceDir)
-	files, err := scanner.Scan()
+	files, err := scanner.Scan(ctx)
+	if ctx.Err() != nil {
+		return r.finish(ctx, &organizer.Statistics{TotalFiles: len(files), StartTime: startTime}, nil, nil, startTime)
+	}
 	if err != nil {
 		errorMsg := i18n.Tf("silent.scan_failed", err.Error())
 		return fmt.Errorf("%s", errorMsg)
@@ -69,6 +92,51 @@ func (r *SilentRunner) Run() error {
 
 	fmt.Println(i18n.Tf("silent.files_found", len(files)))
 
+	// Checkpoint progress so an interrupted run can be resumed
+	var previous *organizer.Statistics
+	if !r.config.DryRun {
+		if r.checkpoint, err = organizer.OpenCheckpoint(r.config, files, r.config.Resume); err != nil {
+			return fmt.Errorf("failed to create checkpoint: %w", err)
+		}
+		if r.checkpoint.Resumed() {
+			previous = r.checkpoint.Previous()
+			r.checkpoint.Restore(r.processor)
+			files = r.checkpoint.Pending(files)
+			fmt.Println(i18n.Tf("silent.resumed", previous.ProcessedFiles, len(files)))
+		} else if r.config.Resume {
+			fmt.Println(i18n.T("silent.resume_unavailable"))
+		}
+	}
+
+	// Import only one copy of identical files within the source.
+	// A resumed run reuses the recorded decisions instead of hashing the source again.
+	if r.checkpoint == nil || !r.checkpoint.SourceDeduped() {
+		duplicates, err := r.processor.DedupeSource(ctx, files)
+		if ctx.Err() != nil {
+			return r.finish(ctx, &organizer.Statistics{TotalFiles: len(files), StartTime: startTime}, nil, nil, startTime)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to dedupe source: %w", err)
+		}
+		if duplicates > 0 {
+			fmt.Println(i18n.Tf("silent.source_duplicates", duplicates))
+		}
+		if r.checkpoint != nil {
+			if err := r.checkpoint.RecordSourceDuplicates(files); err != nil {
+				r.logger.LogError(i18n.Tf("silent.checkpoint_failed", err.Error()))
+			}
+		}
+	}
+
+	// Journal every change so the run can be undone
+	var journal *organizer.Journal
+	if !r.config.DryRun {
+		if journal, err = organizer.NewJournal(); err != nil {
+			return fmt.Errorf("failed to create journal: %w", err)
+		}
+		r.processor.SetJournal(journal)
+	}
+
 	// Initialize statistics
 	stats := &organizer.Statistics{
 		TotalFiles:     len(files),
@@ -79,10 +147,20 @@ func (r *SilentRunner) Run() error {
 		FailedCount:    0,
 		StartTime:      startTime,
 	}
+	// Continue counting from the interrupted run
+	if previous != nil {
+		stats.Merge(previous)
+	}
 
-	// Process each file
+	// Process files concurrently; results arrive in file order
 	var records []organizer.ProcessRecord
-	for i, file := range files {
+	for result := range r.processor.ProcessAll(ctx, files, r.config.Workers) {
+		i, file, record, err := result.Index, files[result.Index], result.Record, result.Err
+		if record == nil && ctx.Err() != nil {
+			// Cancelled before the file was processed; a resumed run picks it up
+			continue
+		}
+
 		// Update statistics based on file type
 		if file.Type == organizer.FileTypePhoto {
 			stats.PhotoCount++
@@ -90,25 +168,26 @@ func (r *SilentRunner) Run() error {
 			stats.VideoCount++
 		}
 
-		// Process the file
-		record, err := r.processor.Process(file)
 		if err != nil {
 			r.logger.LogError(i18n.Tf("silent.process_file_failed", file.Path, err))
 		}
 
 		if record != nil {
 			records = append(records, *record)
+			stats.AddDateSource(record.File.DateSource)
 
 			// Update statistics based on result
-			switch record.Result {
-			case organizer.ResultSuccess:
-				// Success count is calculated as ProcessedFiles - SkippedCount - FailedCount
-			case organizer.ResultSkipped:
-				stats.SkippedCount++
-			case organizer.ResultFailed:
-				stats.FailedCount++
+			// Success count is calculated as ProcessedFiles - SkippedCount - FailedCount
+			stats.AddResult(record)
+			if record.Result == organizer.ResultFailed {
 				r.logger.LogError(i18n.Tf("silent.file_process_failed", file.Path, record.Message))
 			}
+
+			if r.checkpoint != nil {
+				if err := r.checkpoint.Record(record); err != nil {
+					r.logger.LogError(i18n.Tf("silent.checkpoint_failed", err.Error()))
+				}
+			}
 		}
 
 		stats.ProcessedFiles++
@@ -119,9 +198,26 @@ func (r *SilentRunner) Run() error {
 		}
 	}
 
-	// Finalize statistics
+	return r.finish(ctx, stats, records, journal, startTime)
+}
+
+// finish finalizes the statistics, prints the summary and writes the log.
+// It also runs when the scan or source dedupe is cancelled, so every run ends with a summary.
+func (r *SilentRunner) finish(ctx context.Context, stats *organizer.Statistics, records []organizer.ProcessRecord, journal *organizer.Journal, startTime time.Time) error {
+	// Finalize statistics (the duration of an interrupted run is already included)
 	stats.EndTime = time.Now()
-	stats.Duration = time.Since(startTime)
+	stats.Duration += time.Since(startTime)
+	stats.Cancelled = ctx.Err() != nil
+	stats.HashTiers.Add(r.processor.HashTiers())
+
+	// Keep the checkpoint of a cancelled run so it can be resumed
+	if r.checkpoint != nil {
+		if stats.Cancelled {
+			r.checkpoint.Close()
+		} else {
+			r.checkpoint.Remove()
+		}
+	}
 
 	fmt.Println() // Add newline after progress
 
@@ -130,12 +226,27 @@ func (r *SilentRunner) Run() error {
 		r.logger.LogRecord(&record)
 	}
 
+	// Print the plan in dry-run mode
+	if r.config.DryRun {
+		r.printPlan(records)
+	}
+
 	// Print final summary
 	r.printSummary(stats)
 
 	elapsed := time.Since(startTime)
-	fmt.Println(i18n.Tf("silent.completed", elapsed))
+	if stats.Cancelled {
+		fmt.Println(i18n.Tf("silent.cancelled", elapsed))
+		if r.checkpoint != nil {
+			fmt.Println(i18n.T("silent.resume_hint"))
+		}
+	} else {
+		fmt.Println(i18n.Tf("silent.completed", elapsed))
+	}
 	fmt.Println(i18n.Tf("silent.log_saved", r.logger.GetPath()))
+	if journal != nil {
+		closeJournal(journal, r.logger)
+	}
 
 	// Log statistics to file
 	r.logger.LogStatistics(stats)
@@ -146,6 +257,27 @@ func (r *SilentRunner) Run() error {
 	return nil
 }
 
+// cleanupTempFiles removes stale temporary files from the target directories
+func cleanupTempFiles(processor *organizer.Processor, log *logger.Logger) {
+	removed, err := processor.CleanupTempFiles()
+	if err != nil {
+		log.LogError(i18n.Tf("silent.temp_cleanup_failed", err.Error()))
+	}
+	if removed > 0 {
+		fmt.Println(i18n.Tf("silent.temp_cleaned", removed))
+		log.LogInfo(i18n.Tf("silent.temp_cleaned", removed))
+	}
+}
+
+// closeJournal closes the journal and reports where it was saved
+func closeJournal(journal *organizer.Journal, log *logger.Logger) {
+	if err := journal.Close(); err != nil {
+		fmt.Println(i18n.Tf("silent.journal_failed", err.Error()))
+		log.LogError(i18n.Tf("silent.journal_failed", err.Error()))
+	}
+	fmt.Println(i18n.Tf("silent.journal_saved", journal.GetPath()))
+}
+
 // printProgress displays progress updates
 func (r *SilentRunner) printProgress(stats *organizer.Statistics) {
 	if stats.TotalFiles == 0 {
@@ -160,6 +292,24 @@ func (r *SilentRunner) printProgress(stats *organizer.Statistics) {
 	fmt.Print("\r" + progressMsg)
 }
 
+// printPlan displays the planned action for every file
+func (r *SilentRunner) printPlan(records []organizer.ProcessRecord) {
+	fmt.Println()
+	fmt.Println(i18n.T("silent.plan_title"))
+	for _, record := range records {
+		switch {
+		case record.Result == organizer.ResultFailed:
+			fmt.Println(i18n.Tf("silent.plan_skipped", i18n.T("action.failed"), record.File.Path, record.Message))
+		case record.Result == organizer.ResultSkipped:
+			fmt.Println(i18n.Tf("silent.plan_skipped", i18n.T("action.skip"), record.File.Path, record.Message))
+		default:
+			fmt.Println(i18n.Tf("silent.plan_item",
+				i18n.T("action."+string(record.Action)), i18n.T("transfer."+string(record.Transfer)),
+				record.File.Path, record.File.TargetPath))
+		}
+	}
+}
+
 // printSummary displays final summary
 func (r *SilentRunner) printSummary(stats *organizer.Statistics) {
 	fmt.Println()
@@ -170,23 +320,59 @@ func (r *SilentRunner) printSummary(stats *organizer.Statistics) {
 	fmt.Println(i18n.Tf("silent.success_count", stats.ProcessedFiles-stats.SkippedCount-stats.FailedCount))
 	fmt.Println(i18n.Tf("silent.failed_count", stats.FailedCount))
 	fmt.Println(i18n.Tf("silent.skipped_count", stats.SkippedCount))
+	if stats.Cancelled {
+		fmt.Println(i18n.Tf("silent.unprocessed_count", stats.TotalFiles-stats.ProcessedFiles))
+	}
+
+	fmt.Println(i18n.T("silent.destinations"))
+	fmt.Println(i18n.Tf("silent.destination_item", i18n.T("file.photo"), stats.WrittenCounts[organizer.FileTypePhoto],
+		r.config.TargetDirFor(string(organizer.FileTypePhoto))))
+	fmt.Println(i18n.Tf("silent.destination_item", i18n.T("file.video"), stats.WrittenCounts[organizer.FileTypeVideo],
+		r.config.TargetDirFor(string(organizer.FileTypeVideo))))
+
+	if tiers := stats.HashTiers; tiers.Total() > 0 {
+		fmt.Println(i18n.Tf("silent.hash_tiers", tiers.Size, tiers.Partial, tiers.Full))
+	}
+
+	if len(stats.DateSourceCounts) > 0 {
+		fmt.Println(i18n.T("silent.date_sources"))
+		for _, source := range organizer.DateSources {
+			if count := stats.DateSourceCounts[source]; count > 0 {
+				fmt.Println(i18n.Tf("silent.date_source_item",
+					i18n.T("date_source."+string(source)), count,
+					fmt.Sprintf("%.1f", stats.DateSourcePercent(source))))
+			}
+		}
+	}
 
 	if stats.FailedCount > 0 {
 		fmt.Println("\n" + i18n.T("silent.failed_notice"))
 	}
 
+	if stats.DateSourceCounts[organizer.DateSourceMtime] > 0 {
+		fmt.Println("\n" + i18n.Tf("silent.mtime_notice",
+			fmt.Sprintf("%.1f", stats.DateSourcePercent(organizer.DateSourceMtime))))
+	}
+
 	fmt.Println("\n" + i18n.Tf("silent.strategy_used", string(r.config.DuplicateStrategy)))
 }
 
-// handleInterrupt sets up graceful interrupt handling
-func (r *SilentRunner) handleInterrupt() {
+// handleInterrupt returns a context that is cancelled on the first interrupt.
+// The run then stops at the next chunk boundary and still writes its summary;
+// a second interrupt terminates the process immediately.
+func handleInterrupt() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		<-c
-		fmt.Println("\n\n" + i18n.T("silent.interrupt_received"))
-		// TODO: Implement proper stop mechanism when processor supports it
-		os.Exit(1)
+		select {
+		case <-c:
+			fmt.Println("\n\n" + i18n.T("silent.interrupt_received"))
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(c)
 	}()
+	return ctx, cancel
 }
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
+	"github.com/chiyiangel/media-organizer-v2/internal/logger"
+	"github.com/chiyiangel/media-organizer-v2/internal/organizer"
+)
+
+// UndoRunner reverts a previous run from its journal
+type UndoRunner struct {
+	logger *logger.Logger
+}
+
+// NewUndoRunner creates a new undo runner
+func NewUndoRunner() (*UndoRunner, error) {
+	log, err := logger.NewLogger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+
+	return &UndoRunner{logger: log}, nil
+}
+
+// Undo reverses every journaled action, newest first
+func (r *UndoRunner) Undo(path string) error {
+	defer r.logger.Close()
+
+	entries, err := organizer.ReadJournal(path)
+	if err != nil {
+		return err
+	}
+	fmt.Println(i18n.Tf("undo.start", path, len(entries)))
+
+	succeeded, skipped, failed := 0, 0, 0
+	for _, record := range organizer.Undo(entries) {
+		line := fmt.Sprintf("%s %s: %s", record.Entry.Op, record.Entry.Path, record.Message)
+		switch record.Result {
+		case organizer.ResultSuccess:
+			succeeded++
+			r.logger.LogInfo(line)
+		case organizer.ResultSkipped:
+			skipped++
+			r.logger.LogInfo(line)
+			fmt.Println(i18n.Tf("undo.item_skipped", record.Entry.Path, record.Message))
+		case organizer.ResultFailed:
+			failed++
+			r.logger.LogError(line)
+			fmt.Println(i18n.Tf("undo.item_failed", record.Entry.Path, record.Message))
+		}
+	}
+
+	fmt.Println(i18n.Tf("undo.summary", succeeded, skipped, failed))
+	fmt.Println(i18n.Tf("silent.log_saved", r.logger.GetPath()))
+	return nil
+}
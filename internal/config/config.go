@@ -3,6 +3,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/pathtemplate"
 )
 
 // OperationMode defines how the application runs
@@ -27,9 +32,42 @@ type DuplicateDetection string
 
 const (
 	DetectionFilename DuplicateDetection = "filename" // 文件名
-	DetectionMD5      DuplicateDetection = "md5"      // MD5哈希
+	DetectionHash     DuplicateDetection = "hash"     // 内容哈希（算法见 Config.HashAlgorithm）
+	DetectionLibrary  DuplicateDetection = "library"  // 目标库内容索引：内容与库中任意文件相同即为重复
+
+	DetectionPerceptual DuplicateDetection = "perceptual" // 感知哈希：缩放、重新压缩后的相似照片也视为重复
+)
+
+// 旧版本按算法命名的内容哈希策略，等同于 hash 加对应的 HashAlgorithm
+const (
+	DetectionMD5     DuplicateDetection = "md5"
+	DetectionSHA256  DuplicateDetection = "sha256"
+	DetectionCRC64   DuplicateDetection = "crc64"
+	DetectionFNV128a DuplicateDetection = "fnv128a"
+)
+
+// 内容摘要算法
+const (
+	HashMD5     = "md5"     // MD5（默认）
+	HashSHA256  = "sha256"  // SHA-256，适合长期归档清单
+	HashCRC64   = "crc64"   // CRC-64（ECMA），非加密的快速哈希
+	HashFNV128a = "fnv128a" // FNV-1a 128 位，非加密的快速哈希
 )
 
+// DefaultHashAlgorithm 未配置时使用的内容摘要算法
+const DefaultHashAlgorithm = HashMD5
+
+// HashAlgorithms 可用的内容摘要算法（按 TUI 切换顺序）
+var HashAlgorithms = []string{
+	HashMD5,
+	HashSHA256,
+	HashCRC64,
+	HashFNV128a,
+}
+
+// DefaultPerceptualThreshold 感知哈希默认的最大汉明距离（64 位哈希）
+const DefaultPerceptualThreshold = 10
+
 // DuplicateStrategy 重复文件处理策略
 type DuplicateStrategy string
 
@@ -39,12 +77,94 @@ const (
 	StrategyRename    DuplicateStrategy = "rename"    // 重命名
 )
 
+// TransferMode 文件传输方式
+type TransferMode string
+
+const (
+	TransferCopy     TransferMode = "copy"     // 复制
+	TransferMove     TransferMode = "move"     // 移动（跨设备时复制、校验后删除源文件）
+	TransferHardlink TransferMode = "hardlink" // 硬链接
+	TransferSymlink  TransferMode = "symlink"  // 符号链接
+	TransferReflink  TransferMode = "reflink"  // 写时复制克隆，不支持时回退为复制
+)
+
+// TransferModes 可用的传输方式（按 TUI 切换顺序）
+var TransferModes = []TransferMode{
+	TransferCopy,
+	TransferMove,
+	TransferHardlink,
+	TransferSymlink,
+	TransferReflink,
+}
+
+// 归档目录使用的时区
+const (
+	TimezoneSource = "source" // 拍摄地当地时间（默认）
+	TimezoneUTC    = "utc"    // UTC
+)
+
+// 日期来源名称，用于配置按文件类型的日期回退链
+const (
+	DateSourceEXIFOriginal = "exif.DateTimeOriginal" // EXIF 拍摄时间
+	DateSourceEXIFCreate   = "exif.CreateDate"       // EXIF 数字化时间（DateTimeDigitized）
+	DateSourceEXIFModify   = "exif.DateTime"         // EXIF 修改时间
+	DateSourceQuickTime    = "quicktime"             // MP4/MOV 容器创建时间
+	DateSourceXMP          = "xmp"                   // XMP 附属文件
+	DateSourceFilename     = "filename"              // 文件名中的日期
+	DateSourceMtime        = "mtime"                 // 文件修改时间
+)
+
+// validDateSources 可用的日期来源
+var validDateSources = map[string]bool{
+	DateSourceEXIFOriginal: true,
+	DateSourceEXIFCreate:   true,
+	DateSourceEXIFModify:   true,
+	DateSourceQuickTime:    true,
+	DateSourceXMP:          true,
+	DateSourceFilename:     true,
+	DateSourceMtime:        true,
+}
+
+// defaultDateSources 默认的日期回退链（键为文件类型）
+var defaultDateSources = map[string][]string{
+	"photo": {DateSourceEXIFOriginal, DateSourceEXIFCreate, DateSourceEXIFModify, DateSourceXMP, DateSourceFilename, DateSourceMtime},
+	"video": {DateSourceQuickTime, DateSourceXMP, DateSourceFilename, DateSourceMtime},
+}
+
 // Config 应用配置
 type Config struct {
 	SourceDir          string             // 源目录
 	TargetDir          string             // 目标目录
 	DuplicateDetection DuplicateDetection // 重复识别策略
+	HashAlgorithm      string             // 内容摘要算法（所有识别策略、校验和操作日志共用），为空时使用 md5
 	DuplicateStrategy  DuplicateStrategy  // 重复处理策略
+	TransferMode       TransferMode       // 文件传输方式
+	DryRun             bool               // 演练模式：只生成计划，不修改文件系统
+	PreserveTimes      bool               // 复制时保留源文件的修改时间和访问时间
+	PreserveMode       bool               // 复制时保留源文件的权限
+	PreserveXattrs     bool               // 复制时保留扩展属性（Linux/macOS）
+	MtimeFromDate      bool               // 将目标文件的修改时间设为提取到的拍摄日期
+	Verify             bool               // 复制后重新读取目标并与源数据哈希比较
+	Resume             bool               // 从上次中断的检查点继续（静默模式）
+	Workers            int                // 并发处理的工作协程数，0 表示使用 CPU 核数
+	FilenamePatterns   []string           // 额外的文件名日期正则（需包含 year/month/day 命名分组）
+
+	PerceptualThreshold  int  // 感知哈希判定相似的最大汉明距离（1-64），0 表示使用默认值
+	KeepHigherResolution bool // 感知哈希模式下相似照片只保留分辨率较高的一张
+
+	DateSources          map[string][]string // 按文件类型（photo/video）配置的日期来源顺序
+	DisableMtimeFallback bool                // 不回退到修改时间，没有可靠日期的文件视为无日期
+
+	FolderTimezone string // 归档目录时区: source（拍摄地时间）、utc 或 IANA 时区名
+	DayStartHour   int    // 一天的起始小时（0-23），早于该时间的文件归入前一天
+
+	PathTemplate  string // 目标路径模板，为空时使用默认的 {year}/{month}/{month}-{day}/{name}{ext}
+	RenamePattern string // 文件重命名模式（例如 {date:20060102_150405}_{seq:03}{ext}），为空时保留原文件名
+
+	PhotoTargetDir    string // 照片目标目录，为空时使用 TargetDir
+	VideoTargetDir    string // 视频目标目录，为空时使用 TargetDir
+	PhotoPathTemplate string // 照片路径模板，为空时使用 PathTemplate
+	VideoPathTemplate string // 视频路径模板，为空时使用 PathTemplate
 
 	// New fields for silent mode and configuration management
 	Mode       OperationMode // Operation mode (interactive/silent)
@@ -59,6 +179,7 @@ func NewDefaultConfig() *Config {
 		TargetDir:          "",
 		DuplicateDetection: DetectionFilename,
 		DuplicateStrategy:  StrategySkip,
+		TransferMode:       TransferCopy,
 		Mode:               ModeInteractive,
 		ConfigFile:         "",
 		LogLevel:           "info",
@@ -78,7 +199,7 @@ func (c *Config) Validate() error {
 		if c.SourceDir == "" {
 			return fmt.Errorf("在静默模式下，源目录不能为空")
 		}
-		if c.TargetDir == "" {
+		if c.TargetDirFor("photo") == "" || c.TargetDirFor("video") == "" {
 			return fmt.Errorf("在静默模式下，目标目录不能为空")
 		}
 	} else {
@@ -98,6 +219,69 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate duplicate detection
+	if c.DuplicateDetection != "" && !c.DuplicateDetection.Valid() {
+		return fmt.Errorf("无效的重复识别策略: %s (有效值: filename, hash, library, perceptual)", c.DuplicateDetection)
+	}
+
+	// Validate hash algorithm
+	if c.HashAlgorithm != "" && !ValidHashAlgorithm(c.HashAlgorithm) {
+		return fmt.Errorf("无效的哈希算法: %s (有效值: md5, sha256, crc64, fnv128a)", c.HashAlgorithm)
+	}
+	if legacy := c.DuplicateDetection.legacyAlgorithm(); legacy != "" && c.HashAlgorithm != "" && c.HashAlgorithm != legacy {
+		return fmt.Errorf("重复识别策略 %s 与哈希算法 %s 冲突，请使用 hash 并设置哈希算法", c.DuplicateDetection, c.HashAlgorithm)
+	}
+
+	// Validate perceptual threshold
+	if c.PerceptualThreshold < 0 || c.PerceptualThreshold > 64 {
+		return fmt.Errorf("无效的感知哈希阈值: %d (有效值: 1-64)", c.PerceptualThreshold)
+	}
+
+	// Validate transfer mode
+	if c.TransferMode != "" && !c.TransferMode.Valid() {
+		return fmt.Errorf("无效的传输方式: %s (有效值: copy, move, hardlink, symlink, reflink)", c.TransferMode)
+	}
+
+	// Validate filename date patterns
+	for _, pattern := range c.FilenamePatterns {
+		if err := validateFilenamePattern(pattern); err != nil {
+			return err
+		}
+	}
+
+	// Validate date source chains
+	for fileType, sources := range c.DateSources {
+		if _, ok := defaultDateSources[fileType]; !ok {
+			return fmt.Errorf("无效的日期来源文件类型: %s (有效值: photo, video)", fileType)
+		}
+		for _, source := range sources {
+			if !validDateSources[source] {
+				return fmt.Errorf("无效的日期来源: %s", source)
+			}
+		}
+	}
+
+	// Validate folder timezone
+	if _, err := c.FolderLocation(); err != nil {
+		return fmt.Errorf("无效的归档时区: %s", c.FolderTimezone)
+	}
+	if c.Workers < 0 {
+		return fmt.Errorf("无效的并发数: %d", c.Workers)
+	}
+	if c.DayStartHour < 0 || c.DayStartHour > 23 {
+		return fmt.Errorf("无效的一天起始小时: %d (有效值: 0-23)", c.DayStartHour)
+	}
+
+	// Validate path templates
+	for _, fileType := range []string{"photo", "video"} {
+		if _, err := c.TemplateFor(fileType); err != nil {
+			return fmt.Errorf("无效的路径模板: %v", err)
+		}
+	}
+	if _, err := c.RenameTemplate(); err != nil {
+		return fmt.Errorf("无效的重命名模式: %v", err)
+	}
+
 	// Validate log level
 	validLogLevels := map[string]bool{
 		"debug":   true,
@@ -111,3 +295,149 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+// Valid 判断重复识别策略是否有效
+func (d DuplicateDetection) Valid() bool {
+	switch d {
+	case DetectionFilename, DetectionHash, DetectionLibrary, DetectionPerceptual:
+		return true
+	}
+	return d.legacyAlgorithm() != ""
+}
+
+// ContentHash 判断是否为内容哈希策略（包括旧版本按算法命名的值）
+func (d DuplicateDetection) ContentHash() bool {
+	return d == DetectionHash || d.legacyAlgorithm() != ""
+}
+
+// legacyAlgorithm 返回旧版本按算法命名的策略对应的算法，其他策略返回空字符串
+func (d DuplicateDetection) legacyAlgorithm() string {
+	if ValidHashAlgorithm(string(d)) {
+		return string(d)
+	}
+	return ""
+}
+
+// ValidHashAlgorithm 判断内容摘要算法是否有效
+func ValidHashAlgorithm(name string) bool {
+	for _, algorithm := range HashAlgorithms {
+		if name == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// DigestAlgorithm 返回计算内容摘要使用的算法，所有识别策略以及校验、操作日志都使用它
+// 未设置 HashAlgorithm 时使用旧版本识别策略值中的算法，都没有时使用 md5
+func (c *Config) DigestAlgorithm() string {
+	if c.HashAlgorithm != "" {
+		return c.HashAlgorithm
+	}
+	if legacy := c.DuplicateDetection.legacyAlgorithm(); legacy != "" {
+		return legacy
+	}
+	return DefaultHashAlgorithm
+}
+
+// SimilarityThreshold 返回感知哈希判定相似的最大汉明距离
+func (c *Config) SimilarityThreshold() int {
+	if c.PerceptualThreshold == 0 {
+		return DefaultPerceptualThreshold
+	}
+	return c.PerceptualThreshold
+}
+
+// Valid 判断传输方式是否有效
+func (m TransferMode) Valid() bool {
+	for _, mode := range TransferModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// DateSourcesFor 返回指定文件类型的日期回退链
+// 启用 DisableMtimeFallback 时会移除 mtime
+func (c *Config) DateSourcesFor(fileType string) []string {
+	sources := c.DateSources[fileType]
+	if len(sources) == 0 {
+		sources = defaultDateSources[fileType]
+	}
+
+	result := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if source == DateSourceMtime && c.DisableMtimeFallback {
+			continue
+		}
+		result = append(result, source)
+	}
+	return result
+}
+
+// FolderLocation 返回归档目录使用的时区，nil 表示保留拍摄地时间
+func (c *Config) FolderLocation() (*time.Location, error) {
+	switch strings.ToLower(c.FolderTimezone) {
+	case "", TimezoneSource:
+		return nil, nil
+	case TimezoneUTC:
+		return time.UTC, nil
+	default:
+		return time.LoadLocation(c.FolderTimezone)
+	}
+}
+
+// TargetDirFor 返回指定文件类型的目标目录，未单独配置时使用 TargetDir
+func (c *Config) TargetDirFor(fileType string) string {
+	switch {
+	case fileType == "photo" && c.PhotoTargetDir != "":
+		return c.PhotoTargetDir
+	case fileType == "video" && c.VideoTargetDir != "":
+		return c.VideoTargetDir
+	}
+	return c.TargetDir
+}
+
+// TemplateFor 解析指定文件类型的路径模板
+// 回退顺序: 按类型的模板 → PathTemplate → 默认模板
+func (c *Config) TemplateFor(fileType string) (*pathtemplate.Template, error) {
+	layout := c.PathTemplate
+	switch {
+	case fileType == "photo" && c.PhotoPathTemplate != "":
+		layout = c.PhotoPathTemplate
+	case fileType == "video" && c.VideoPathTemplate != "":
+		layout = c.VideoPathTemplate
+	}
+	if layout == "" {
+		layout = pathtemplate.DefaultLayout
+	}
+	return pathtemplate.Parse(layout)
+}
+
+// RenameTemplate 解析文件重命名模式，未配置时返回 nil
+func (c *Config) RenameTemplate() (*pathtemplate.Template, error) {
+	if c.RenamePattern == "" {
+		return nil, nil
+	}
+	return pathtemplate.ParseName(c.RenamePattern)
+}
+
+// validateFilenamePattern 校验文件名日期正则
+func validateFilenamePattern(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("无效的文件名日期模式: %s (%v)", pattern, err)
+	}
+
+	groups := map[string]bool{}
+	for _, name := range re.SubexpNames() {
+		groups[name] = true
+	}
+	for _, required := range []string{"year", "month", "day"} {
+		if !groups[required] {
+			return fmt.Errorf("文件名日期模式缺少命名分组 %s: %s", required, pattern)
+		}
+	}
+	return nil
+}
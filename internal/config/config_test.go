@@ -0,0 +1,163 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{"Default", func(c *Config) {}, false},
+		{"Filename pattern", func(c *Config) {
+			c.FilenamePatterns = []string{`(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})`}
+		}, false},
+		{"Filename pattern missing group", func(c *Config) {
+			c.FilenamePatterns = []string{`(?P<year>\d{4})(?P<month>\d{2})`}
+		}, true},
+		{"Date source chain", func(c *Config) {
+			c.DateSources = map[string][]string{"photo": {"exif.DateTimeOriginal", "xmp", "filename"}}
+		}, false},
+		{"Unknown date source", func(c *Config) {
+			c.DateSources = map[string][]string{"photo": {"exif.Foo"}}
+		}, true},
+		{"Unknown date source type", func(c *Config) {
+			c.DateSources = map[string][]string{"audio": {"mtime"}}
+		}, true},
+		{"UTC folder timezone", func(c *Config) { c.FolderTimezone = "UTC" }, false},
+		{"Named folder timezone", func(c *Config) { c.FolderTimezone = "Asia/Shanghai" }, false},
+		{"Unknown folder timezone", func(c *Config) { c.FolderTimezone = "Mars/Olympus" }, true},
+		{"Day start hour", func(c *Config) { c.DayStartHour = 4 }, false},
+		{"Day start hour out of range", func(c *Config) { c.DayStartHour = 24 }, true},
+		{"Transfer mode", func(c *Config) { c.TransferMode = TransferReflink }, false},
+		{"Unknown transfer mode", func(c *Config) { c.TransferMode = "teleport" }, true},
+		{"Hash detection", func(c *Config) { c.DuplicateDetection = DetectionHash; c.HashAlgorithm = HashSHA256 }, false},
+		{"Legacy hash detection", func(c *Config) { c.DuplicateDetection = DetectionSHA256 }, false},
+		{"Library with hash algorithm", func(c *Config) { c.DuplicateDetection = DetectionLibrary; c.HashAlgorithm = HashCRC64 }, false},
+		{"Unknown hash algorithm", func(c *Config) { c.HashAlgorithm = "sha1" }, true},
+		{"Legacy detection conflicts with hash algorithm", func(c *Config) {
+			c.DuplicateDetection = DetectionSHA256
+			c.HashAlgorithm = HashMD5
+		}, true},
+		{"Unknown detection", func(c *Config) { c.DuplicateDetection = "sha1" }, true},
+		{"Perceptual detection", func(c *Config) { c.DuplicateDetection = DetectionPerceptual; c.PerceptualThreshold = 6 }, false},
+		{"Perceptual threshold out of range", func(c *Config) { c.PerceptualThreshold = 65 }, true},
+		{"Path template", func(c *Config) {
+			c.PathTemplate = "{year}/{month:02}-{monthname}/{date:2006-01-02}_{camera}/{name}{ext}"
+		}, false},
+		{"Unknown path template token", func(c *Config) { c.PathTemplate = "{year}/{album}/{name}{ext}" }, true},
+		{"Unclosed path template token", func(c *Config) { c.PathTemplate = "{year/{name}{ext}" }, true},
+		{"Rename pattern", func(c *Config) { c.RenamePattern = "{date:20060102_150405}_{seq:03}{ext}" }, false},
+		{"Silent mode with type roots", func(c *Config) {
+			c.Mode = ModeSilent
+			c.SourceDir = "."
+			c.PhotoTargetDir = "Photos"
+			c.VideoTargetDir = "Videos"
+		}, false},
+		{"Silent mode missing video root", func(c *Config) {
+			c.Mode = ModeSilent
+			c.SourceDir = "."
+			c.PhotoTargetDir = "Photos"
+		}, true},
+		{"Invalid video path template", func(c *Config) { c.VideoPathTemplate = "{year}/{foo}" }, true},
+		{"Rename pattern with directory", func(c *Config) { c.RenamePattern = "{year}/{name}{ext}" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDefaultConfig()
+			tt.modify(c)
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDigestAlgorithm(t *testing.T) {
+	tests := []struct {
+		name      string
+		detection DuplicateDetection
+		algorithm string
+		want      string
+	}{
+		{"Default", DetectionFilename, "", HashMD5},
+		{"Hash", DetectionHash, HashFNV128a, HashFNV128a},
+		{"Library", DetectionLibrary, HashSHA256, HashSHA256},
+		{"Perceptual", DetectionPerceptual, HashCRC64, HashCRC64},
+		{"Legacy detection", DetectionSHA256, "", HashSHA256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDefaultConfig()
+			c.DuplicateDetection = tt.detection
+			c.HashAlgorithm = tt.algorithm
+			if got := c.DigestAlgorithm(); got != tt.want {
+				t.Errorf("DigestAlgorithm() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeLegacyDetection(t *testing.T) {
+	// 旧版本的 sha256 策略转换为 hash，并覆盖低优先级配置中的算法
+	file := &Config{HashAlgorithm: HashCRC64}
+	cli := &Config{DuplicateDetection: DetectionSHA256}
+	merged := MergeConfigs(NewDefaultConfig(), file, cli)
+	if merged.DuplicateDetection != DetectionHash || merged.HashAlgorithm != HashSHA256 {
+		t.Errorf("MergeConfigs() = %s/%s, want hash/sha256", merged.DuplicateDetection, merged.HashAlgorithm)
+	}
+	if err := merged.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	// 高优先级配置只设置算法时保留低优先级的识别策略
+	file = &Config{DuplicateDetection: DetectionLibrary}
+	cli = &Config{HashAlgorithm: HashFNV128a}
+	merged = MergeConfigs(NewDefaultConfig(), file, cli)
+	if merged.DuplicateDetection != DetectionLibrary || merged.DigestAlgorithm() != HashFNV128a {
+		t.Errorf("MergeConfigs() = %s/%s, want library/fnv128a", merged.DuplicateDetection, merged.DigestAlgorithm())
+	}
+}
+
+func TestDateSourcesFor(t *testing.T) {
+	c := NewDefaultConfig()
+	c.DisableMtimeFallback = true
+
+	for _, source := range c.DateSourcesFor("video") {
+		if source == DateSourceMtime {
+			t.Errorf("DateSourcesFor() should drop mtime when DisableMtimeFallback is set")
+		}
+	}
+	if got := c.DateSourcesFor("photo"); len(got) == 0 || got[0] != DateSourceEXIFOriginal {
+		t.Errorf("DateSourcesFor(photo) = %v, want default chain", got)
+	}
+}
+
+func TestTargetSplit(t *testing.T) {
+	c := NewDefaultConfig()
+	c.TargetDir = "Library"
+	c.VideoTargetDir = "Videos"
+	c.PathTemplate = "{year}/{name}{ext}"
+	c.VideoPathTemplate = "{type}/{name}{ext}"
+
+	if got := c.TargetDirFor("photo"); got != "Library" {
+		t.Errorf("TargetDirFor(photo) = %s, want Library", got)
+	}
+	if got := c.TargetDirFor("video"); got != "Videos" {
+		t.Errorf("TargetDirFor(video) = %s, want Videos", got)
+	}
+
+	photo, err := c.TemplateFor("photo")
+	if err != nil || photo.String() != c.PathTemplate {
+		t.Errorf("TemplateFor(photo) = %v, %v, want %s", photo, err, c.PathTemplate)
+	}
+	video, err := c.TemplateFor("video")
+	if err != nil || video.String() != c.VideoPathTemplate {
+		t.Errorf("TemplateFor(video) = %v, %v, want %s", video, err, c.VideoPathTemplate)
+	}
+}
@@ -60,12 +60,76 @@ func MergeConfigs(defaults, file, cli *Config) *Config {
 		if file.TargetDir != "" {
 			result.TargetDir = file.TargetDir
 		}
-		if file.DuplicateDetection != "" {
-			result.DuplicateDetection = file.DuplicateDetection
-		}
+		mergeDetection(result, file)
 		if file.DuplicateStrategy != "" {
 			result.DuplicateStrategy = file.DuplicateStrategy
 		}
+		if file.TransferMode != "" {
+			result.TransferMode = file.TransferMode
+		}
+		if file.DryRun {
+			result.DryRun = true
+		}
+		if file.PreserveTimes {
+			result.PreserveTimes = true
+		}
+		if file.PreserveMode {
+			result.PreserveMode = true
+		}
+		if file.PreserveXattrs {
+			result.PreserveXattrs = true
+		}
+		if file.MtimeFromDate {
+			result.MtimeFromDate = true
+		}
+		if file.Verify {
+			result.Verify = true
+		}
+		if file.Resume {
+			result.Resume = true
+		}
+		if file.Workers != 0 {
+			result.Workers = file.Workers
+		}
+		if file.PerceptualThreshold != 0 {
+			result.PerceptualThreshold = file.PerceptualThreshold
+		}
+		if file.KeepHigherResolution {
+			result.KeepHigherResolution = true
+		}
+		if len(file.FilenamePatterns) > 0 {
+			result.FilenamePatterns = file.FilenamePatterns
+		}
+		if len(file.DateSources) > 0 {
+			result.DateSources = file.DateSources
+		}
+		if file.DisableMtimeFallback {
+			result.DisableMtimeFallback = true
+		}
+		if file.FolderTimezone != "" {
+			result.FolderTimezone = file.FolderTimezone
+		}
+		if file.DayStartHour != 0 {
+			result.DayStartHour = file.DayStartHour
+		}
+		if file.PathTemplate != "" {
+			result.PathTemplate = file.PathTemplate
+		}
+		if file.RenamePattern != "" {
+			result.RenamePattern = file.RenamePattern
+		}
+		if file.PhotoTargetDir != "" {
+			result.PhotoTargetDir = file.PhotoTargetDir
+		}
+		if file.VideoTargetDir != "" {
+			result.VideoTargetDir = file.VideoTargetDir
+		}
+		if file.PhotoPathTemplate != "" {
+			result.PhotoPathTemplate = file.PhotoPathTemplate
+		}
+		if file.VideoPathTemplate != "" {
+			result.VideoPathTemplate = file.VideoPathTemplate
+		}
 		if file.Mode != "" {
 			result.Mode = file.Mode
 		}
@@ -85,12 +149,76 @@ func MergeConfigs(defaults, file, cli *Config) *Config {
 		if cli.TargetDir != "" {
 			result.TargetDir = cli.TargetDir
 		}
-		if cli.DuplicateDetection != "" {
-			result.DuplicateDetection = cli.DuplicateDetection
-		}
+		mergeDetection(result, cli)
 		if cli.DuplicateStrategy != "" {
 			result.DuplicateStrategy = cli.DuplicateStrategy
 		}
+		if cli.TransferMode != "" {
+			result.TransferMode = cli.TransferMode
+		}
+		if cli.DryRun {
+			result.DryRun = true
+		}
+		if cli.PreserveTimes {
+			result.PreserveTimes = true
+		}
+		if cli.PreserveMode {
+			result.PreserveMode = true
+		}
+		if cli.PreserveXattrs {
+			result.PreserveXattrs = true
+		}
+		if cli.MtimeFromDate {
+			result.MtimeFromDate = true
+		}
+		if cli.Verify {
+			result.Verify = true
+		}
+		if cli.Resume {
+			result.Resume = true
+		}
+		if cli.Workers != 0 {
+			result.Workers = cli.Workers
+		}
+		if cli.PerceptualThreshold != 0 {
+			result.PerceptualThreshold = cli.PerceptualThreshold
+		}
+		if cli.KeepHigherResolution {
+			result.KeepHigherResolution = true
+		}
+		if len(cli.FilenamePatterns) > 0 {
+			result.FilenamePatterns = cli.FilenamePatterns
+		}
+		if len(cli.DateSources) > 0 {
+			result.DateSources = cli.DateSources
+		}
+		if cli.DisableMtimeFallback {
+			result.DisableMtimeFallback = true
+		}
+		if cli.FolderTimezone != "" {
+			result.FolderTimezone = cli.FolderTimezone
+		}
+		if cli.DayStartHour != 0 {
+			result.DayStartHour = cli.DayStartHour
+		}
+		if cli.PathTemplate != "" {
+			result.PathTemplate = cli.PathTemplate
+		}
+		if cli.RenamePattern != "" {
+			result.RenamePattern = cli.RenamePattern
+		}
+		if cli.PhotoTargetDir != "" {
+			result.PhotoTargetDir = cli.PhotoTargetDir
+		}
+		if cli.VideoTargetDir != "" {
+			result.VideoTargetDir = cli.VideoTargetDir
+		}
+		if cli.PhotoPathTemplate != "" {
+			result.PhotoPathTemplate = cli.PhotoPathTemplate
+		}
+		if cli.VideoPathTemplate != "" {
+			result.VideoPathTemplate = cli.VideoPathTemplate
+		}
 		if cli.Mode != "" {
 			result.Mode = cli.Mode
 		}
@@ -145,3 +273,19 @@ func LoadFullConfig(cliConfig *Config) (*Config, error) {
 
 	return finalConfig, nil
 }
+
+// mergeDetection 合并重复识别策略和哈希算法
+// 旧版本按算法命名的策略（例如 sha256）转换为 hash 加对应的哈希算法，覆盖低优先级配置中的算法；
+// 同一来源同时设置了不同的算法时保留原值，由 Validate 报告冲突
+func mergeDetection(result, layer *Config) {
+	if layer.DuplicateDetection != "" {
+		result.DuplicateDetection = layer.DuplicateDetection
+		if legacy := layer.DuplicateDetection.legacyAlgorithm(); legacy != "" && (layer.HashAlgorithm == "" || layer.HashAlgorithm == legacy) {
+			result.DuplicateDetection = DetectionHash
+			result.HashAlgorithm = legacy
+		}
+	}
+	if layer.HashAlgorithm != "" {
+		result.HashAlgorithm = layer.HashAlgorithm
+	}
+}
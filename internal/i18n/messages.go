@@ -14,16 +14,35 @@ func (l *Localizer) initMessages() {
 			"config.not_set":            "未设置",
 			"config.edit_source_hint":   "           按 [S] 编辑路径",
 			"config.edit_target_hint":   "           按 [D] 编辑路径",
+			"config.photo_target_dir":   "    📷 照片: ",
+			"config.video_target_dir":   "    🎬 视频: ",
+			"config.same_as_target":     "同目标目录",
+			"config.edit_type_hint":     "           按 [P] 编辑照片目录  |  按 [V] 编辑视频目录",
+			"config.path_template":      "🗂  路径模板: ",
+			"config.edit_template_hint": "           按 [T] 编辑模板",
+			"config.rename_pattern":     "✏️  重命名:   ",
+			"config.rename_disabled":    "保留原文件名",
+			"config.edit_rename_hint":   "           按 [N] 编辑重命名模式",
 			"config.organize_strategy":  "⚙️  整理策略:",
-			"config.file_detection":     "    同文件识别: [F] 文件名 {0}  [M] MD5哈希 {1}",
+			"config.file_detection":     "    同文件识别: [F] 文件名 {0}  [M] 内容哈希 {1}  [L] 目标库内容 {2}",
+			"config.hash_algorithm":     "    哈希算法:   [A] {0}",
+			"config.similar_photos":     "    相似照片:   [I] 感知哈希 {0}  [H] 只保留高分辨率 {1}",
 			"config.duplicate_handling": "    重复处理:   [1] 跳过 {0}  [2] 覆盖 {1}  [3] 重命名 {2}",
+			"config.transfer_mode":      "    传输方式:   [X] {0}",
+			"config.dry_run":            "    演练模式:   [Y] {0}",
+			"config.on":                 "开（只生成计划，不修改文件）",
+			"config.off":                "关",
 			"config.start_hint":         "按 [Enter] 开始整理  |  按 [Q/Esc] 退出程序",
 			"config.start_hint_wrapped": "按 [Enter] 开始整理\n按 [Q/Esc] 退出程序",
 
 			// 输入界面
-			"input.title":        "📝 编辑路径",
-			"input.prompt":       "请输入目录路径:",
-			"input.confirm_hint": "按 [Enter] 确认  |  按 [Esc] 取消",
+			"input.title":           "📝 编辑路径",
+			"input.prompt":          "请输入目录路径:",
+			"input.confirm_hint":    "按 [Enter] 确认  |  按 [Esc] 取消",
+			"input.template_title":  "📝 编辑路径模板",
+			"input.template_prompt": "请输入路径模板（例如 {year}/{month:02}/{date:2006-01-02}_{camera}/{name}{ext}，留空使用默认）:",
+			"input.rename_title":    "📝 编辑重命名模式",
+			"input.rename_prompt":   "请输入文件名模式（例如 {date:20060102_150405}_{seq:03}{ext}，留空保留原文件名）:",
 
 			// 进度界面
 			"progress.title":        "🔄 正在整理文件...",
@@ -38,6 +57,7 @@ func (l *Localizer) initMessages() {
 			"progress.skipped":      "    ├─ 跳过: {0} 个 (重复)",
 			"progress.errors":       "    └─ 错误: {0} 个",
 			"progress.cancel_hint":  "按 [C/Esc] 取消整理",
+			"progress.cancelling":   "正在取消，等待进行中的文件停止...（再按 Ctrl+C 立即退出）",
 
 			// 汇总界面
 			"summary.title":                "✅ 整理完成!",
@@ -50,36 +70,101 @@ func (l *Localizer) initMessages() {
 			"summary.success":              "    ✓ 成功整理:    {0} 个",
 			"summary.skipped":              "    ⊘ 跳过(重复):  {0} 个",
 			"summary.failed":               "    ✗ 失败:        {0} 个",
+			"summary.unprocessed":          "    … 未处理:      {0} 个",
+			"summary.cancelled_title":      "⚠️ 整理已取消",
+			"summary.hash_tiers":           "    内容比较: 按大小 {0} | 部分哈希 {1} | 完整哈希 {2}",
+			"summary.date_sources":         "日期来源:",
+			"summary.date_source_item":     "    {0}: {1} 个 ({2}%)",
+			"summary.mtime_warning":        "    ⚠ 部分文件按修改时间归档，日期可能不准确",
+			"summary.destinations":         "目标目录:",
+			"summary.dry_run_title":        "📝 演练完成（未修改任何文件）",
+			"summary.plan":                 "计划 ({0} 项):",
+			"summary.plan_item":            "    [{0}] {1} → {2}",
+			"summary.plan_more":            "    ... 其余 {0} 项见日志",
+			"summary.destination_item":     "    {0} ({1}) → {2}",
 			"summary.performance":          "性能数据:",
 			"summary.duration":             "    耗时:          {0}",
 			"summary.speed":                "    处理速度:      {0} 文件/秒",
 			"summary.log_file":             "💾 详细日志: ",
+			"summary.journal_file":         "↩️  操作记录: ",
 			"summary.actions_hint":         "按 [R] 重新整理  |  按 [O] 打开目标目录  |  按 [Q/Esc] 退出",
 			"summary.actions_hint_wrapped": "按 [R] 重新整理  |  按 [O] 打开目标目录\n按 [Q/Esc] 退出",
 
 			// 错误信息和消息
-			"error.prefix":              "错误: ",
-			"error.extract_date":        "无法提取日期: {0}",
-			"error.check_duplicate":     "检查重复失败: {0}",
-			"error.copy_file":           "复制文件失败: {0}",
-			"message.duplicate_skipped": "重复文件，已跳过",
-			"message.success":           "成功处理",
+			"error.prefix":                "错误: ",
+			"error.extract_date":          "无法提取日期: {0}",
+			"error.check_duplicate":       "检查重复失败: {0}",
+			"error.copy_file":             "复制文件失败: {0}",
+			"error.transfer_file":         "文件传输失败 ({0}): {1}",
+			"error.target_path":           "生成目标路径失败: {0}",
+			"error.target_not_set":        "请设置目标目录（或分别设置照片和视频目录）",
+			"message.duplicate_skipped":   "重复文件，已跳过",
+			"message.library_duplicate":   "目标库中已有相同内容的文件，已跳过: {0}",
+			"message.source_duplicate":    "与源目录中的 {0} 重复，已跳过",
+			"message.similar_skipped":     "与目标库中的 {0} 相似（距离 {1}），已跳过",
+			"message.similar_lower":       "目标库中已有分辨率更高的相似照片，已跳过: {0}",
+			"message.similar_imported":    "成功处理，与目标库中的 {0} 相似",
+			"message.similar_replaced":    "成功处理，已替换分辨率较低的 {0}",
+			"message.undated":             "没有可靠的日期，已跳过",
+			"message.success":             "成功处理",
+			"message.planned":             "已计划（演练模式）",
+			"message.plan_skipped":        "计划中标记为跳过",
+			"error.source_changed":        "源文件在生成计划后发生变化: {0}",
+			"error.target_exists":         "目标文件已存在",
+			"error.target_write":          "写入目标失败: {0}",
+			"error.verify_failed":         "校验失败，已删除不一致的副本: {0}",
+			"error.similar_replace":       "已导入，但无法移走分辨率较低的 {0}: {1}",
+			"error.plan_no_target":        "计划条目缺少目标路径",
+			"error.plan_invalid_action":   "计划条目的操作无效: {0}",
+			"error.plan_invalid_transfer": "计划条目的传输方式无效: {0}",
+
+			// 日期来源
+			"date_source.exif":      "EXIF",
+			"date_source.quicktime": "视频元数据",
+			"date_source.filename":  "文件名",
+			"date_source.sidecar":   "XMP附属文件",
+			"date_source.mtime":     "修改时间",
 
 			// 文件类型
 			"file.photo": "照片",
 			"file.video": "视频",
 			"file.other": "其他",
 
+			// 传输方式
+			"transfer.copy":     "复制",
+			"transfer.move":     "移动",
+			"transfer.hardlink": "硬链接",
+			"transfer.symlink":  "符号链接",
+			"transfer.reflink":  "克隆 (reflink，不支持时复制)",
+
+			// 计划操作
+			"action.transfer":  "新增",
+			"action.overwrite": "覆盖",
+			"action.rename":    "改名",
+			"action.skip":      "跳过",
+			"action.failed":    "失败",
+
 			// Silent mode 相关
 			"silent.start":               "开始静默模式媒体整理",
 			"silent.source_dir":          "源目录: {0}",
 			"silent.target_dir":          "目标目录: {0}",
+			"silent.photo_target_dir":    "照片目标目录: {0}",
+			"silent.video_target_dir":    "视频目标目录: {0}",
 			"silent.duplicate_detection": "重复识别策略: {0}",
+			"silent.hash_algorithm":      "哈希算法: {0}",
 			"silent.duplicate_strategy":  "重复处理策略: {0}",
+			"silent.transfer_mode":       "传输方式: {0}",
+			"silent.dry_run":             "演练模式: 只生成计划，不修改任何文件",
+			"silent.temp_cleaned":        "已清理上次中断遗留的 {0} 个临时文件",
+			"silent.temp_cleanup_failed": "清理临时文件失败: {0}",
+			"silent.plan_title":          "=== 计划 ===",
+			"silent.plan_item":           "[{0}/{1}] {2} → {3}",
+			"silent.plan_skipped":        "[{0}] {1}: {2}",
 			"silent.scan_start":          "开始扫描文件...",
 			"silent.scan_failed":         "文件扫描失败: {0}",
 			"silent.no_media_files":      "未找到支持的媒体文件",
 			"silent.files_found":         "找到 {0} 个媒体文件，开始处理...",
+			"silent.source_duplicates":   "源目录中有 {0} 个重复文件，每组只导入一份",
 			"silent.process_file_failed": "处理文件失败: {0}, 错误: {1}",
 			"silent.file_process_failed": "文件处理失败: {0}, 原因: {1}",
 			"silent.progress":            "进度: {0}/{1} ({2}%) | 成功: {3} | 失败: {4} | 跳过: {5}",
@@ -90,11 +175,47 @@ func (l *Localizer) initMessages() {
 			"silent.success_count":       "成功处理: {0}",
 			"silent.failed_count":        "处理失败: {0}",
 			"silent.skipped_count":       "跳过文件: {0}",
+			"silent.unprocessed_count":   "未处理: {0}",
 			"silent.failed_notice":       "注意: 有文件处理失败，请查看日志文件了解详情",
+			"silent.hash_tiers":          "内容比较: 按大小区分 {0} 次 | 部分哈希 {1} 次 | 完整哈希 {2} 次",
+			"silent.date_sources":        "日期来源:",
+			"silent.date_source_item":    "  {0}: {1} ({2}%)",
+			"silent.mtime_notice":        "注意: {0}% 的文件按修改时间归档，日期可能不准确",
+			"silent.destinations":        "目标目录:",
+			"silent.destination_item":    "  {0} ({1}) → {2}",
 			"silent.strategy_used":       "重复文件处理策略: {0}",
 			"silent.completed":           "处理完成，耗时: {0}",
+			"silent.cancelled":           "处理已取消，耗时: {0}",
 			"silent.log_saved":           "详细日志已保存到: {0}",
+			"silent.journal_saved":       "操作记录已保存到: {0}（可使用 undo 撤销）",
+			"silent.journal_failed":      "写入操作记录失败: {0}",
 			"silent.interrupt_received":  "接收到中断信号，正在停止...",
+			"silent.resume_hint":         "进度已保存，使用 -resume 可从中断处继续",
+			"silent.resumed":             "从检查点继续: 已处理 {0} 个文件，剩余 {1} 个",
+			"silent.resume_unavailable":  "没有与当前配置和源目录匹配的检查点，从头开始",
+			"silent.checkpoint_failed":   "写入检查点失败: {0}",
+
+			// plan/apply 命令
+			"plan.saved":         "计划已保存到: {0}",
+			"plan.summary":       "计划 {0} 项: 执行 {1}，跳过 {2}；{3} 个文件无法计划（见日志）",
+			"plan.apply_start":   "执行计划: {0}（{1} 项）",
+			"plan.apply_summary": "执行完成: 成功 {0}，跳过 {1}，失败 {2}",
+			"plan.apply_failed":  "  ✗ {0}: {1}",
+
+			// undo 命令
+			"undo.start":          "撤销操作: {0}（{1} 项）",
+			"undo.summary":        "撤销完成: 成功 {0}，跳过 {1}，失败 {2}",
+			"undo.item_skipped":   "  - {0}: {1}",
+			"undo.item_failed":    "  ✗ {0}: {1}",
+			"undo.removed":        "已删除",
+			"undo.moved_back":     "已移回原位置",
+			"undo.restored":       "已恢复被覆盖的文件",
+			"undo.missing":        "文件不存在",
+			"undo.changed":        "文件内容已变化，保留不动",
+			"undo.source_exists":  "原位置已有文件，保留不动",
+			"undo.dir_not_empty":  "目录非空，保留不动",
+			"undo.restore_failed": "恢复被覆盖的文件失败: {0}",
+			"undo.unknown_op":     "未知的操作: {0}",
 		},
 
 		LanguageEnglish: {
@@ -108,16 +229,35 @@ func (l *Localizer) initMessages() {
 			"config.not_set":            "Not Set",
 			"config.edit_source_hint":   "           Press [S] to edit path",
 			"config.edit_target_hint":   "           Press [D] to edit path",
+			"config.photo_target_dir":   "    📷 Photos: ",
+			"config.video_target_dir":   "    🎬 Videos: ",
+			"config.same_as_target":     "Same as target",
+			"config.edit_type_hint":     "           Press [P] to edit photo path  |  Press [V] to edit video path",
+			"config.path_template":      "🗂  Path Template: ",
+			"config.edit_template_hint": "           Press [T] to edit template",
+			"config.rename_pattern":     "✏️  Rename: ",
+			"config.rename_disabled":    "Keep original names",
+			"config.edit_rename_hint":   "           Press [N] to edit rename pattern",
 			"config.organize_strategy":  "⚙️  Organization Strategy:",
-			"config.file_detection":     "    File Detection: [F] Filename {0}  [M] MD5 Hash {1}",
+			"config.file_detection":     "    File Detection: [F] Filename {0}  [M] Content Hash {1}  [L] Library Content {2}",
+			"config.hash_algorithm":     "    Hash Algorithm: [A] {0}",
+			"config.similar_photos":     "    Similar Photos: [I] Perceptual Hash {0}  [H] Keep Higher Resolution {1}",
 			"config.duplicate_handling": "    Duplicate Action: [1] Skip {0}  [2] Overwrite {1}  [3] Rename {2}",
+			"config.transfer_mode":      "    Transfer Mode:    [X] {0}",
+			"config.dry_run":            "    Dry Run:          [Y] {0}",
+			"config.on":                 "On (plan only, no files are changed)",
+			"config.off":                "Off",
 			"config.start_hint":         "Press [Enter] to start  |  Press [Q/Esc] to quit",
 			"config.start_hint_wrapped": "Press [Enter] to start\nPress [Q/Esc] to quit",
 
 			// Input screen
-			"input.title":        "📝 Edit Path",
-			"input.prompt":       "Please enter directory path:",
-			"input.confirm_hint": "Press [Enter] to confirm  |  Press [Esc] to cancel",
+			"input.title":           "📝 Edit Path",
+			"input.prompt":          "Please enter directory path:",
+			"input.confirm_hint":    "Press [Enter] to confirm  |  Press [Esc] to cancel",
+			"input.template_title":  "📝 Edit Path Template",
+			"input.template_prompt": "Please enter path template (e.g. {year}/{month:02}/{date:2006-01-02}_{camera}/{name}{ext}, empty for default):",
+			"input.rename_title":    "📝 Edit Rename Pattern",
+			"input.rename_prompt":   "Please enter file name pattern (e.g. {date:20060102_150405}_{seq:03}{ext}, empty to keep original names):",
 
 			// Progress screen
 			"progress.title":        "🔄 Organizing Files...",
@@ -132,6 +272,7 @@ func (l *Localizer) initMessages() {
 			"progress.skipped":      "    ├─ Skipped: {0} (duplicates)",
 			"progress.errors":       "    └─ Errors: {0}",
 			"progress.cancel_hint":  "Press [C/Esc] to cancel",
+			"progress.cancelling":   "Cancelling, waiting for files in progress to stop... (press Ctrl+C again to quit)",
 
 			// Summary screen
 			"summary.title":                "✅ Organization Complete!",
@@ -144,36 +285,101 @@ func (l *Localizer) initMessages() {
 			"summary.success":              "    ✓ Successfully organized: {0}",
 			"summary.skipped":              "    ⊘ Skipped (duplicates):  {0}",
 			"summary.failed":               "    ✗ Failed:                {0}",
+			"summary.unprocessed":          "    … Not processed:         {0}",
+			"summary.cancelled_title":      "⚠️ Organization Cancelled",
+			"summary.hash_tiers":           "    Content comparisons: by size {0} | partial hash {1} | full hash {2}",
+			"summary.date_sources":         "Date Sources:",
+			"summary.date_source_item":     "    {0}: {1} ({2}%)",
+			"summary.mtime_warning":        "    ⚠ Some files were dated by modification time and may be misfiled",
+			"summary.destinations":         "Destinations:",
+			"summary.dry_run_title":        "📝 Dry Run Complete (no files were changed)",
+			"summary.plan":                 "Plan ({0} entries):",
+			"summary.plan_item":            "    [{0}] {1} → {2}",
+			"summary.plan_more":            "    ... {0} more entries in the log",
+			"summary.destination_item":     "    {0} ({1}) → {2}",
 			"summary.performance":          "Performance Data:",
 			"summary.duration":             "    Duration:         {0}",
 			"summary.speed":                "    Processing Speed: {0} files/sec",
 			"summary.log_file":             "💾 Detailed Log: ",
+			"summary.journal_file":         "↩️  Journal: ",
 			"summary.actions_hint":         "Press [R] to restart  |  Press [O] to open folder  |  Press [Q/Esc] to quit",
 			"summary.actions_hint_wrapped": "Press [R] to restart  |  Press [O] to open folder\nPress [Q/Esc] to quit",
 
 			// Error messages and processing messages
-			"error.prefix":              "Error: ",
-			"error.extract_date":        "Failed to extract date: {0}",
-			"error.check_duplicate":     "Failed to check duplicate: {0}",
-			"error.copy_file":           "Failed to copy file: {0}",
-			"message.duplicate_skipped": "Duplicate file skipped",
-			"message.success":           "Successfully processed",
+			"error.prefix":                "Error: ",
+			"error.extract_date":          "Failed to extract date: {0}",
+			"error.check_duplicate":       "Failed to check duplicate: {0}",
+			"error.copy_file":             "Failed to copy file: {0}",
+			"error.transfer_file":         "Failed to transfer file ({0}): {1}",
+			"error.target_path":           "Failed to build target path: {0}",
+			"error.target_not_set":        "Please set the target directory (or both photo and video directories)",
+			"message.duplicate_skipped":   "Duplicate file skipped",
+			"message.library_duplicate":   "Same content already in the library, skipped: {0}",
+			"message.source_duplicate":    "Duplicate of {0} in source, skipped",
+			"message.similar_skipped":     "Similar to {0} in the library (distance {1}), skipped",
+			"message.similar_lower":       "A higher-resolution similar photo is already in the library, skipped: {0}",
+			"message.similar_imported":    "Successfully processed, similar to {0} in the library",
+			"message.similar_replaced":    "Successfully processed, replaced lower-resolution {0}",
+			"message.undated":             "No trusted date found, skipped",
+			"message.success":             "Successfully processed",
+			"message.planned":             "Planned (dry run)",
+			"message.plan_skipped":        "Marked as skip in the plan",
+			"error.source_changed":        "Source changed since the plan was made: {0}",
+			"error.target_exists":         "Target file already exists",
+			"error.target_write":          "Failed to write target: {0}",
+			"error.verify_failed":         "Verification failed, the bad copy was removed: {0}",
+			"error.similar_replace":       "Imported, but could not move away lower-resolution {0}: {1}",
+			"error.plan_no_target":        "Plan entry has no target path",
+			"error.plan_invalid_action":   "Invalid action in plan entry: {0}",
+			"error.plan_invalid_transfer": "Invalid transfer mode in plan entry: {0}",
+
+			// Date sources
+			"date_source.exif":      "EXIF",
+			"date_source.quicktime": "Video metadata",
+			"date_source.filename":  "Filename",
+			"date_source.sidecar":   "XMP sidecar",
+			"date_source.mtime":     "Modification time",
 
 			// File types
 			"file.photo": "Photo",
 			"file.video": "Video",
 			"file.other": "Other",
 
+			// Transfer modes
+			"transfer.copy":     "Copy",
+			"transfer.move":     "Move",
+			"transfer.hardlink": "Hard link",
+			"transfer.symlink":  "Symbolic link",
+			"transfer.reflink":  "Clone (reflink, copy if unsupported)",
+
+			// Plan actions
+			"action.transfer":  "new",
+			"action.overwrite": "overwrite",
+			"action.rename":    "rename",
+			"action.skip":      "skip",
+			"action.failed":    "failed",
+
 			// Silent mode related
 			"silent.start":               "Starting silent mode media organization",
 			"silent.source_dir":          "Source directory: {0}",
 			"silent.target_dir":          "Target directory: {0}",
+			"silent.photo_target_dir":    "Photo target directory: {0}",
+			"silent.video_target_dir":    "Video target directory: {0}",
 			"silent.duplicate_detection": "Duplicate detection strategy: {0}",
+			"silent.hash_algorithm":      "Hash algorithm: {0}",
 			"silent.duplicate_strategy":  "Duplicate handling strategy: {0}",
+			"silent.transfer_mode":       "Transfer mode: {0}",
+			"silent.dry_run":             "Dry run: producing a plan only, no files will be changed",
+			"silent.temp_cleaned":        "Removed {0} temporary files left by an interrupted run",
+			"silent.temp_cleanup_failed": "Failed to clean up temporary files: {0}",
+			"silent.plan_title":          "=== Plan ===",
+			"silent.plan_item":           "[{0}/{1}] {2} → {3}",
+			"silent.plan_skipped":        "[{0}] {1}: {2}",
 			"silent.scan_start":          "Starting file scan...",
 			"silent.scan_failed":         "File scan failed: {0}",
 			"silent.no_media_files":      "No supported media files found",
 			"silent.files_found":         "Found {0} media files, starting processing...",
+			"silent.source_duplicates":   "{0} files in the source are duplicates, importing one copy of each",
 			"silent.process_file_failed": "Failed to process file: {0}, error: {1}",
 			"silent.file_process_failed": "File processing failed: {0}, reason: {1}",
 			"silent.progress":            "Progress: {0}/{1} ({2}%) | Success: {3} | Failed: {4} | Skipped: {5}",
@@ -184,23 +390,87 @@ func (l *Localizer) initMessages() {
 			"silent.success_count":       "Successfully processed: {0}",
 			"silent.failed_count":        "Failed to process: {0}",
 			"silent.skipped_count":       "Skipped files: {0}",
+			"silent.unprocessed_count":   "Not processed: {0}",
 			"silent.failed_notice":       "Note: Some files failed to process, check log file for details",
+			"silent.hash_tiers":          "Content comparisons: by size {0} | partial hash {1} | full hash {2}",
+			"silent.date_sources":        "Date sources:",
+			"silent.date_source_item":    "  {0}: {1} ({2}%)",
+			"silent.mtime_notice":        "Note: {0}% of files were dated by modification time and may be misfiled",
+			"silent.destinations":        "Destinations:",
+			"silent.destination_item":    "  {0} ({1}) → {2}",
 			"silent.strategy_used":       "Duplicate handling strategy used: {0}",
 			"silent.completed":           "Processing completed, elapsed time: {0}",
+			"silent.cancelled":           "Processing cancelled, elapsed time: {0}",
 			"silent.log_saved":           "Detailed log saved to: {0}",
+			"silent.journal_saved":       "Journal saved to: {0} (revert with undo)",
+			"silent.journal_failed":      "Failed to write journal: {0}",
 			"silent.interrupt_received":  "Interrupt signal received, stopping...",
+			"silent.resume_hint":         "Progress saved; run again with -resume to continue",
+			"silent.resumed":             "Resuming from checkpoint: {0} files already processed, {1} remaining",
+			"silent.resume_unavailable":  "No checkpoint matches the current configuration and source, starting from the beginning",
+			"silent.checkpoint_failed":   "Failed to write checkpoint: {0}",
+
+			// plan/apply commands
+			"plan.saved":         "Plan saved to: {0}",
+			"plan.summary":       "Plan has {0} entries: {1} to apply, {2} skipped; {3} files could not be planned (see log)",
+			"plan.apply_start":   "Applying plan: {0} ({1} entries)",
+			"plan.apply_summary": "Apply finished: {0} succeeded, {1} skipped, {2} failed",
+			"plan.apply_failed":  "  ✗ {0}: {1}",
+
+			// undo command
+			"undo.start":          "Undoing: {0} ({1} entries)",
+			"undo.summary":        "Undo finished: {0} succeeded, {1} skipped, {2} failed",
+			"undo.item_skipped":   "  - {0}: {1}",
+			"undo.item_failed":    "  ✗ {0}: {1}",
+			"undo.removed":        "Removed",
+			"undo.moved_back":     "Moved back to the original location",
+			"undo.restored":       "Restored the overwritten file",
+			"undo.missing":        "File does not exist",
+			"undo.changed":        "File content changed, left in place",
+			"undo.source_exists":  "Original location is occupied, left in place",
+			"undo.dir_not_empty":  "Directory is not empty, left in place",
+			"undo.restore_failed": "Failed to restore the overwritten file: {0}",
+			"undo.unknown_op":     "Unknown operation: {0}",
 
 			// CLI messages
 			"cli.help.title":              "Media Organizer v{0}",
-			"cli.help.usage":              "Usage: organizer [options]",
+			"cli.help.usage":              "Usage: organizer [options]\n       organizer plan [options] [-out plan.json]\n       organizer apply <plan.json>\n       organizer undo <journal.jsonl>",
+			"cli.commands":                "Commands:",
+			"cli.command.plan":            "Plan every file without changing anything and save the plan as JSON",
+			"cli.command.apply":           "Apply a (possibly edited) plan file; changed sources are reported as failures",
+			"cli.command.undo":            "Revert a run from its journal; files changed since are left in place",
+			"cli.option.plan_out":         "Plan file to write (plan command)",
+			"cli.error.apply_usage":       "Usage: organizer apply [options] <plan.json>",
+			"cli.error.plan":              "Failed to create plan: {0}",
+			"cli.error.apply":             "Failed to apply plan: {0}",
+			"cli.error.undo_usage":        "Usage: organizer undo <journal.jsonl>",
+			"cli.error.undo":              "Failed to undo: {0}",
 			"cli.options.core":            "Core options:",
 			"cli.options.silent":          "Silent mode options:",
 			"cli.options.info":            "Information options:",
 			"cli.examples":                "Examples:",
 			"cli.option.source":           "Source directory path",
 			"cli.option.target":           "Target directory path",
-			"cli.option.detection":        "Duplicate detection strategy (filename, md5)",
+			"cli.option.detection":        "Duplicate detection strategy (filename, hash, library, perceptual)",
+			"cli.option.hash":             "Hash algorithm for content comparison, verification and the journal (md5, sha256, crc64, fnv128a; default: md5)",
 			"cli.option.strategy":         "Duplicate handling strategy (skip, overwrite, rename)",
+			"cli.option.transfer":         "Transfer mode (copy, move, hardlink, symlink, reflink)",
+			"cli.option.dry_run":          "Print the plan without changing any files",
+			"cli.option.preserve_times":   "Keep the source modification and access times on copies",
+			"cli.option.preserve_mode":    "Keep the source file permissions on copies",
+			"cli.option.preserve_xattrs":  "Keep extended attributes such as Finder tags (Linux/macOS)",
+			"cli.option.mtime_from_date":  "Set the target modification time to the extracted date",
+			"cli.option.verify":           "Re-read every copy and compare it with the source hash",
+			"cli.option.resume":           "Continue an interrupted silent run from its checkpoint",
+			"cli.option.workers":          "Number of files processed in parallel (default: number of CPUs)",
+			"cli.option.similarity":       "Max Hamming distance for perceptual matches, 1-64 (default: 10)",
+			"cli.option.keep_higher":      "With perceptual detection, keep only the higher-resolution photo",
+			"cli.option.template":         "Target path template (e.g. {year}/{month:02}/{name}{ext})",
+			"cli.option.rename":           "Rename pattern (e.g. {date:20060102_150405}_{seq:03}{ext})",
+			"cli.option.photo_target":     "Photo target directory (defaults to -target)",
+			"cli.option.video_target":     "Video target directory (defaults to -target)",
+			"cli.option.photo_template":   "Photo path template (defaults to -template)",
+			"cli.option.video_template":   "Video path template (defaults to -template)",
 			"cli.option.mode":             "Operation mode (interactive, silent)",
 			"cli.option.silent":           "Enable silent mode (equivalent to --mode silent)",
 			"cli.option.config":           "Configuration file path",
@@ -218,8 +488,12 @@ func (l *Localizer) initMessages() {
 			"cli.error.silent_exec":       "Silent mode execution failed: {0}",
 			"cli.error.invalid_mode":      "Invalid operation mode: {0}",
 			"cli.error.invalid_detection": "Invalid duplicate detection strategy: {0}",
+			"cli.error.invalid_hash":      "Invalid hash algorithm: {0}",
 			"cli.error.invalid_strategy":  "Invalid duplicate handling strategy: {0}",
+			"cli.error.invalid_transfer":  "Invalid transfer mode: {0}",
 			"cli.error.invalid_log_level": "Invalid log level: {0}",
+			"cli.error.invalid_template":  "Invalid path template: {0}",
+			"cli.error.invalid_rename":    "Invalid rename pattern: {0}",
 		},
 	}
 }
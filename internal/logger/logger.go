@@ -65,11 +65,26 @@ func (l *Logger) LogRecord(record *organizer.ProcessRecord) {
 		status = "✗ 失败"
 	}
 
-	line := fmt.Sprintf("[%s] %s | %s -> %s | %s\n",
+	source := string(record.File.DateSource)
+	if source == "" {
+		source = "-"
+	}
+
+	transfer := string(record.Transfer)
+	if transfer == "" {
+		transfer = "-"
+	}
+	if record.Action != "" {
+		transfer = string(record.Action) + "/" + transfer
+	}
+
+	line := fmt.Sprintf("[%s] %s | %s -> %s | 日期来源: %s | 操作: %s | %s\n",
 		timestamp,
 		status,
 		record.File.Name,
 		record.File.TargetPath,
+		source,
+		transfer,
 		record.Message,
 	)
 
@@ -79,7 +94,11 @@ func (l *Logger) LogRecord(record *organizer.ProcessRecord) {
 // LogStatistics 记录统计信息
 func (l *Logger) LogStatistics(stats *organizer.Statistics) {
 	summary := "\n" + "=" + string(make([]byte, 60)) + "\n"
-	summary += "整理完成汇总\n"
+	if stats.Cancelled {
+		summary += "整理已取消汇总\n"
+	} else {
+		summary += "整理完成汇总\n"
+	}
 	summary += "=" + string(make([]byte, 60)) + "\n\n"
 
 	summary += fmt.Sprintf("文件统计:\n")
@@ -90,7 +109,28 @@ func (l *Logger) LogStatistics(stats *organizer.Statistics) {
 	summary += fmt.Sprintf("处理结果:\n")
 	summary += fmt.Sprintf("  ✓ 成功整理:   %d 个\n", stats.ProcessedFiles-stats.SkippedCount-stats.FailedCount)
 	summary += fmt.Sprintf("  ⊘ 跳过(重复): %d 个\n", stats.SkippedCount)
-	summary += fmt.Sprintf("  ✗ 失败:       %d 个\n\n", stats.FailedCount)
+	summary += fmt.Sprintf("  ✗ 失败:       %d 个\n", stats.FailedCount)
+	if stats.Cancelled {
+		summary += fmt.Sprintf("  … 未处理:     %d 个\n", stats.TotalFiles-stats.ProcessedFiles)
+	}
+	summary += "\n"
+
+	if tiers := stats.HashTiers; tiers.Total() > 0 {
+		summary += fmt.Sprintf("内容比较:\n")
+		summary += fmt.Sprintf("  按大小区分:   %d 次\n", tiers.Size)
+		summary += fmt.Sprintf("  部分哈希:     %d 次\n", tiers.Partial)
+		summary += fmt.Sprintf("  完整哈希:     %d 次\n\n", tiers.Full)
+	}
+
+	if len(stats.DateSourceCounts) > 0 {
+		summary += fmt.Sprintf("日期来源:\n")
+		for _, source := range organizer.DateSources {
+			if count := stats.DateSourceCounts[source]; count > 0 {
+				summary += fmt.Sprintf("  %-12s  %d 个 (%.1f%%)\n", source, count, stats.DateSourcePercent(source))
+			}
+		}
+		summary += "\n"
+	}
 
 	summary += fmt.Sprintf("性能数据:\n")
 	summary += fmt.Sprintf("  开始时间:     %s\n", stats.StartTime.Format("2006-01-02 15:04:05"))
@@ -108,6 +148,13 @@ func (l *Logger) LogError(message string) {
 	l.file.WriteString(line)
 }
 
+// LogInfo 记录一般信息
+func (l *Logger) LogInfo(message string) {
+	timestamp := time.Now().Format("15:04:05")
+	line := fmt.Sprintf("[%s] INFO | %s\n", timestamp, message)
+	l.file.WriteString(line)
+}
+
 // Close 关闭日志文件
 func (l *Logger) Close() error {
 	if l.file != nil {
@@ -0,0 +1,17 @@
+//go:build darwin
+
+package organizer
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// accessTime 返回文件的访问时间
+func accessTime(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Atimespec.Unix())
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,123 @@
+package organizer
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 写入目标时使用的临时文件名（与目标位于同一目录，保证可以原子重命名）
+const (
+	tempFilePrefix = ".media-organizer-"
+	tempFileSuffix = ".tmp"
+)
+
+// TargetWriteError 写入目标位置失败（磁盘已满、权限不足等），与读取源文件失败区分
+type TargetWriteError struct {
+	Path string
+	Err  error
+}
+
+func (e *TargetWriteError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *TargetWriteError) Unwrap() error {
+	return e.Err
+}
+
+// targetWriter 将写入错误标记为 TargetWriteError
+type targetWriter struct {
+	file *os.File
+}
+
+func (w targetWriter) Write(b []byte) (int, error) {
+	n, err := w.file.Write(b)
+	if err != nil {
+		err = &TargetWriteError{Path: w.file.Name(), Err: err}
+	}
+	return n, err
+}
+
+// createTempFile 在目标目录中创建临时文件
+func (p *Processor) createTempFile(dst string) (*os.File, error) {
+	if err := p.ensureDir(filepath.Dir(dst)); err != nil {
+		return nil, &TargetWriteError{Path: dst, Err: err}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), tempFilePrefix+"*"+tempFileSuffix)
+	if err != nil {
+		return nil, &TargetWriteError{Path: dst, Err: err}
+	}
+	return tmp, nil
+}
+
+// commitTempFile 将临时文件落盘并重命名到最终路径
+// verify 不为 nil 时在重命名前校验临时文件，校验失败则不会替换目标
+func commitTempFile(tmp *os.File, dst string, verify func(path string) error) error {
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return &TargetWriteError{Path: dst, Err: err}
+	}
+	if err := tmp.Close(); err != nil {
+		return &TargetWriteError{Path: dst, Err: err}
+	}
+	if verify != nil {
+		if err := verify(tmp.Name()); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return &TargetWriteError{Path: dst, Err: err}
+	}
+	syncDir(filepath.Dir(dst))
+	return nil
+}
+
+// syncDir 尽力同步目录项，使重命名在崩溃后仍然有效（部分平台不支持，忽略错误）
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// isTempFile 判断是否为本程序遗留的临时文件
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, tempFilePrefix) && strings.HasSuffix(name, tempFileSuffix)
+}
+
+// CleanupTempFiles 删除之前运行崩溃后残留在目标目录中的临时文件，返回删除数量
+func (p *Processor) CleanupTempFiles() (int, error) {
+	roots := map[string]bool{}
+	for _, fileType := range []FileType{FileTypePhoto, FileTypeVideo} {
+		if root := p.config.TargetDirFor(string(fileType)); root != "" {
+			roots[root] = true
+		}
+	}
+
+	removed := 0
+	for root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// 目标目录尚不存在时无需清理
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() || !isTempFile(d.Name()) {
+				return nil
+			}
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed++
+			return nil
+		})
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
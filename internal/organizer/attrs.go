@@ -0,0 +1,45 @@
+package organizer
+
+import (
+	"os"
+	"time"
+)
+
+// defaultFileMode 未保留源文件权限时目标文件使用的权限
+const defaultFileMode os.FileMode = 0644
+
+// chtimes 修改文件时间戳，测试中可替换以模拟失败
+var chtimes = os.Chtimes
+
+// targetMode 返回目标文件应使用的权限
+func (p *Processor) targetMode(srcInfo os.FileInfo) os.FileMode {
+	if p.config.PreserveMode {
+		return srcInfo.Mode().Perm()
+	}
+	return defaultFileMode
+}
+
+// applyAttributes 按配置将源文件的时间戳和扩展属性应用到目标文件
+// path 为实际写入的文件（复制时为重命名前的临时文件）
+func (p *Processor) applyAttributes(file *FileInfo, srcInfo os.FileInfo, path string) error {
+	if p.config.PreserveXattrs {
+		if err := copyXattrs(file.Path, path); err != nil {
+			return &TargetWriteError{Path: file.TargetPath, Err: err}
+		}
+	}
+
+	if p.config.PreserveTimes || p.config.MtimeFromDate {
+		atime, mtime := time.Now(), time.Now()
+		if p.config.PreserveTimes {
+			atime, mtime = accessTime(srcInfo), srcInfo.ModTime()
+		}
+		// 使用提取到的拍摄日期作为修改时间
+		if p.config.MtimeFromDate && !file.Date.IsZero() {
+			mtime = file.Date
+		}
+		if err := chtimes(path, atime, mtime); err != nil {
+			return &TargetWriteError{Path: file.TargetPath, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package organizer
+
+import (
+	"os"
+	"time"
+)
+
+// accessTime 当前平台无法读取访问时间，使用修改时间
+func accessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
+
+// copyXattrs 当前平台不支持扩展属性
+func copyXattrs(src, dst string) error {
+	return nil
+}
@@ -0,0 +1,294 @@
+package organizer
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ISO-BMFF (MP4/MOV/HEIF/CR3) 盒子解析
+//
+// 盒子结构: size(4) + type(4) [+ largesize(8)] + payload
+// size == 1 表示使用 64 位 largesize，size == 0 表示延伸到父容器末尾
+
+// quickTimeEpochOffset 1904-01-01 与 1970-01-01 之间的秒数
+const quickTimeEpochOffset = 2082844800
+
+var errInvalidBox = errors.New("无效的BMFF盒子")
+
+// bmffBox ISO-BMFF 盒子
+type bmffBox struct {
+	Type  string // 盒子类型（四字符码）
+	Start int64  // 负载起始偏移
+	End   int64  // 盒子结束偏移
+}
+
+// Size 返回负载长度
+func (b bmffBox) Size() int64 {
+	return b.End - b.Start
+}
+
+// readBMFFBoxes 读取 [start, end) 范围内的同级盒子
+func readBMFFBoxes(r io.ReaderAt, start, end int64) ([]bmffBox, error) {
+	var boxes []bmffBox
+	var hdr [8]byte
+
+	for pos := start; pos+8 <= end; {
+		if _, err := r.ReadAt(hdr[:], pos); err != nil {
+			return boxes, err
+		}
+
+		size := int64(binary.BigEndian.Uint32(hdr[:4]))
+		typ := string(hdr[4:8])
+		headerLen := int64(8)
+
+		switch size {
+		case 0:
+			// 延伸到容器末尾
+			size = end - pos
+		case 1:
+			// 64位长度
+			var large [8]byte
+			if _, err := r.ReadAt(large[:], pos+8); err != nil {
+				return boxes, err
+			}
+			size = int64(binary.BigEndian.Uint64(large[:]))
+			headerLen = 16
+		}
+
+		if size < headerLen || pos+size > end {
+			return boxes, errInvalidBox
+		}
+
+		boxes = append(boxes, bmffBox{
+			Type:  typ,
+			Start: pos + headerLen,
+			End:   pos + size,
+		})
+		pos += size
+	}
+
+	return boxes, nil
+}
+
+// findBMFFBox 在同级盒子中查找指定类型
+func findBMFFBox(boxes []bmffBox, typ string) (bmffBox, bool) {
+	for _, box := range boxes {
+		if box.Type == typ {
+			return box, true
+		}
+	}
+	return bmffBox{}, false
+}
+
+// readBMFFChildren 读取容器盒子的子盒子
+func readBMFFChildren(r io.ReaderAt, box bmffBox) ([]bmffBox, error) {
+	return readBMFFBoxes(r, box.Start, box.End)
+}
+
+// readMetaChildren 读取 meta 盒子的子盒子
+// ISO 规范中 meta 是 FullBox（带4字节版本/标志），QuickTime 中则不是
+func readMetaChildren(r io.ReaderAt, meta bmffBox) ([]bmffBox, error) {
+	var probe [8]byte
+	if _, err := r.ReadAt(probe[:], meta.Start); err != nil {
+		return nil, err
+	}
+	if string(probe[4:8]) == "hdlr" {
+		return readBMFFBoxes(r, meta.Start, meta.End)
+	}
+	return readBMFFBoxes(r, meta.Start+4, meta.End)
+}
+
+// readBoxPayload 读取盒子负载（限制最大长度）
+func readBoxPayload(r io.ReaderAt, box bmffBox, limit int64) ([]byte, error) {
+	size := box.Size()
+	if size > limit {
+		return nil, errInvalidBox
+	}
+	buf := make([]byte, size)
+	if _, err := r.ReadAt(buf, box.Start); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readQuickTimeCreationDate 从 MP4/MOV 容器中读取拍摄时间
+// 优先使用 moov/meta 中的 com.apple.quicktime.creationdate（带时区），
+// 其次使用 moov/mvhd 的创建时间（UTC）
+func readQuickTimeCreationDate(r io.ReaderAt, fileSize int64) (time.Time, bool) {
+	top, _ := readBMFFBoxes(r, 0, fileSize)
+	moov, ok := findBMFFBox(top, "moov")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	children, _ := readBMFFChildren(r, moov)
+
+	if meta, ok := findBMFFBox(children, "meta"); ok {
+		if t, ok := readAppleCreationDate(r, meta); ok {
+			return t, true
+		}
+	}
+
+	if mvhd, ok := findBMFFBox(children, "mvhd"); ok {
+		if t, ok := readMvhdCreationTime(r, mvhd); ok {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// readMvhdCreationTime 解析 mvhd 盒子中的创建时间
+// 版本0使用32位时间，版本1使用64位时间，均以1904-01-01 UTC为纪元
+func readMvhdCreationTime(r io.ReaderAt, mvhd bmffBox) (time.Time, bool) {
+	var buf [12]byte
+	if mvhd.Size() < int64(len(buf)) {
+		return time.Time{}, false
+	}
+	if _, err := r.ReadAt(buf[:], mvhd.Start); err != nil {
+		return time.Time{}, false
+	}
+
+	var secs uint64
+	switch buf[0] {
+	case 0:
+		secs = uint64(binary.BigEndian.Uint32(buf[4:8]))
+	case 1:
+		secs = binary.BigEndian.Uint64(buf[4:12])
+	default:
+		return time.Time{}, false
+	}
+
+	// 未设置（0）或早于1970年的值视为无效
+	if secs <= quickTimeEpochOffset || secs > 1<<62 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(int64(secs-quickTimeEpochOffset), 0), true
+}
+
+// readAppleCreationDate 从 meta 盒子的 keys/ilst 中读取
+// com.apple.quicktime.creationdate
+func readAppleCreationDate(r io.ReaderAt, meta bmffBox) (time.Time, bool) {
+	values := readAppleMetadata(r, meta, appleKeyCreationDate)
+	value, ok := values[appleKeyCreationDate]
+	if !ok {
+		return time.Time{}, false
+	}
+	return parseQuickTimeDateString(value)
+}
+
+// QuickTime 元数据键
+const (
+	appleKeyCreationDate = "com.apple.quicktime.creationdate"
+	appleKeyMake         = "com.apple.quicktime.make"
+	appleKeyModel        = "com.apple.quicktime.model"
+)
+
+// readAppleMetadata 读取 moov/meta 中 keys/ilst 形式的字符串元数据
+func readAppleMetadata(r io.ReaderAt, meta bmffBox, names ...string) map[string]string {
+	result := map[string]string{}
+	children, _ := readMetaChildren(r, meta)
+
+	keysBox, ok := findBMFFBox(children, "keys")
+	if !ok {
+		return result
+	}
+	ilstBox, ok := findBMFFBox(children, "ilst")
+	if !ok {
+		return result
+	}
+
+	keys, err := readBoxPayload(r, keysBox, 1<<20)
+	if err != nil || len(keys) < 8 {
+		return result
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	// 键的索引（从1开始）
+	indexes := map[uint32]string{}
+	count := binary.BigEndian.Uint32(keys[4:8])
+	pos := 8
+	for i := uint32(1); i <= count && pos+8 <= len(keys); i++ {
+		keySize := int(binary.BigEndian.Uint32(keys[pos : pos+4]))
+		if keySize < 8 || pos+keySize > len(keys) {
+			break
+		}
+		if name := string(keys[pos+8 : pos+keySize]); wanted[name] {
+			indexes[i] = name
+		}
+		pos += keySize
+	}
+	if len(indexes) == 0 {
+		return result
+	}
+
+	items, _ := readBMFFChildren(r, ilstBox)
+	for _, item := range items {
+		name, ok := indexes[binary.BigEndian.Uint32([]byte(item.Type))]
+		if !ok {
+			continue
+		}
+
+		values, _ := readBMFFChildren(r, item)
+		data, ok := findBMFFBox(values, "data")
+		if !ok {
+			continue
+		}
+
+		// data 负载: 类型(4) + 区域(4) + 值
+		payload, err := readBoxPayload(r, data, 1024)
+		if err != nil || len(payload) <= 8 {
+			continue
+		}
+		result[name] = string(payload[8:])
+	}
+
+	return result
+}
+
+// readQuickTimeCamera 读取 MP4/MOV 中的设备厂商与型号
+func readQuickTimeCamera(r io.ReaderAt, fileSize int64) (string, string) {
+	top, _ := readBMFFBoxes(r, 0, fileSize)
+	moov, ok := findBMFFBox(top, "moov")
+	if !ok {
+		return "", ""
+	}
+
+	children, _ := readBMFFChildren(r, moov)
+	meta, ok := findBMFFBox(children, "meta")
+	if !ok {
+		return "", ""
+	}
+
+	values := readAppleMetadata(r, meta, appleKeyMake, appleKeyModel)
+	return values[appleKeyMake], values[appleKeyModel]
+}
+
+// quickTimeDateLayouts creationdate 常见格式
+var quickTimeDateLayouts = []string{
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02T15:04:05.000Z07:00",
+	"2006-01-02T15:04:05",
+}
+
+// parseQuickTimeDateString 解析 QuickTime 日期字符串
+// 不带时区的值按本地时间解析
+func parseQuickTimeDateString(s string) (time.Time, bool) {
+	s = strings.TrimRight(strings.TrimSpace(s), "\x00")
+	for _, layout := range quickTimeDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
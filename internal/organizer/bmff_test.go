@@ -0,0 +1,104 @@
+package organizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// testBox 构造一个 BMFF 盒子
+func testBox(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	buf := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(buf[:4], uint32(8+len(body)))
+	copy(buf[4:], typ)
+	return append(buf, body...)
+}
+
+func testUint32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+func testUint64(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func TestReadQuickTimeCreationDateMvhd(t *testing.T) {
+	want := time.Date(2021, 3, 4, 10, 15, 30, 0, time.UTC)
+	secs := uint64(want.Unix()) + quickTimeEpochOffset
+
+	tests := []struct {
+		name string
+		mvhd []byte
+	}{
+		{"version 0", testBox("mvhd", []byte{0, 0, 0, 0}, testUint32(uint32(secs)), testUint32(uint32(secs)))},
+		{"version 1", testBox("mvhd", []byte{1, 0, 0, 0}, testUint64(secs), testUint64(secs))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Join([][]byte{
+				testBox("ftyp", []byte("isom")),
+				testBox("mdat", make([]byte, 16)),
+				testBox("moov", tt.mvhd),
+			}, nil)
+
+			got, ok := readQuickTimeCreationDate(bytes.NewReader(data), int64(len(data)))
+			if !ok {
+				t.Fatalf("readQuickTimeCreationDate() found no date")
+			}
+			if !got.Equal(want) {
+				t.Errorf("readQuickTimeCreationDate() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadQuickTimeCreationDateApple(t *testing.T) {
+	key := "com.apple.quicktime.creationdate"
+	keys := testBox("keys",
+		[]byte{0, 0, 0, 0}, testUint32(2),
+		testUint32(uint32(8+len("com.apple.quicktime.make"))), []byte("mdta"), []byte("com.apple.quicktime.make"),
+		testUint32(uint32(8+len(key))), []byte("mdta"), []byte(key),
+	)
+	ilst := testBox("ilst",
+		testBox(string(testUint32(1)), testBox("data", testUint32(1), testUint32(0), []byte("Apple"))),
+		testBox(string(testUint32(2)), testBox("data", testUint32(1), testUint32(0), []byte("2021-03-04T10:15:30+0800"))),
+	)
+	meta := testBox("meta", testBox("hdlr", make([]byte, 24)), keys, ilst)
+	mvhd := testBox("mvhd", []byte{0, 0, 0, 0}, testUint32(quickTimeEpochOffset+1000), testUint32(0))
+	data := bytes.Join([][]byte{testBox("ftyp", []byte("qt  ")), testBox("moov", mvhd, meta)}, nil)
+
+	got, ok := readQuickTimeCreationDate(bytes.NewReader(data), int64(len(data)))
+	if !ok {
+		t.Fatalf("readQuickTimeCreationDate() found no date")
+	}
+	want := time.Date(2021, 3, 4, 10, 15, 30, 0, time.FixedZone("", 8*3600))
+	if !got.Equal(want) {
+		t.Errorf("readQuickTimeCreationDate() = %v, want %v", got, want)
+	}
+}
+
+func TestReadQuickTimeCreationDateMissing(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no moov", testBox("ftyp", []byte("isom"))},
+		{"zero mvhd", testBox("moov", testBox("mvhd", make([]byte, 12)))},
+		{"not bmff", []byte("RIFF\x00\x00\x00\x00AVI LIST")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := readQuickTimeCreationDate(bytes.NewReader(tt.data), int64(len(tt.data))); ok {
+				t.Errorf("readQuickTimeCreationDate() should find no date")
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package organizer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+// checkpointVersion 检查点文件格式版本
+const checkpointVersion = 1
+
+// checkpointHeader 检查点文件的第一行
+type checkpointHeader struct {
+	Version   int       `json:"version"`
+	TreeHash  string    `json:"treeHash"` // 开始时源目录文件列表的哈希
+	StartTime time.Time `json:"startTime"`
+}
+
+// checkpointEntry 已处理的文件（每个文件一行）
+type checkpointEntry struct {
+	Path        string        `json:"path"`
+	Size        int64         `json:"size"`
+	Type        FileType      `json:"type"`
+	Result      ProcessResult `json:"result"`
+	DuplicateOf string        `json:"duplicateOf,omitempty"`
+	DateSource  DateSource    `json:"dateSource,omitempty"`
+	SeqKey      string        `json:"seqKey,omitempty"`
+	Seq         int           `json:"seq,omitempty"`
+	Elapsed     time.Duration `json:"elapsed"` // 截至该文件的累计耗时（包括之前中断的运行）
+	Tiers       HashTiers     `json:"tiers"`   // 截至该文件的累计分级比较计数
+}
+
+// checkpointDedupe 源目录去重的结果（重复文件 → 代表），去重完成后写入一行
+type checkpointDedupe struct {
+	SourceDuplicates map[string]string `json:"sourceDuplicates"`
+}
+
+// Checkpoint 断点续传检查点
+// 以配置为键保存在用户缓存目录中，每处理完一个文件追加一行，中断后可从第一个未处理的文件继续
+type Checkpoint struct {
+	path    string
+	file    *os.File
+	header  checkpointHeader
+	done    map[string]checkpointEntry
+	deduped map[string]string // 源目录去重结果，nil 表示尚未去重
+	resumed bool
+	elapsed time.Duration // 之前运行的累计耗时
+	tiers   HashTiers     // 之前运行的分级比较计数
+	started time.Time     // 本次运行的开始时间
+}
+
+// OpenCheckpoint 打开检查点
+// resume 为 true 且存在配置与源文件都一致的检查点时继续使用，否则重新开始
+func OpenCheckpoint(cfg *config.Config, files []*FileInfo, resume bool) (*Checkpoint, error) {
+	path, err := checkpointPath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	cp := &Checkpoint{path: path, done: make(map[string]checkpointEntry), started: time.Now()}
+	if resume {
+		if header, entries, deduped, err := readCheckpoint(path); err == nil && header.TreeHash == treeHash(files, entries) {
+			cp.header = header
+			cp.deduped = deduped
+			for _, entry := range entries {
+				cp.done[entry.Path] = entry
+				if entry.Elapsed > cp.elapsed {
+					cp.elapsed = entry.Elapsed
+				}
+				if entry.Tiers.Total() > cp.tiers.Total() {
+					cp.tiers = entry.Tiers
+				}
+			}
+			cp.resumed = true
+			cp.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return nil, err
+			}
+			return cp, nil
+		}
+	}
+
+	// 重新开始
+	cp.header = checkpointHeader{Version: checkpointVersion, TreeHash: treeHash(files, nil), StartTime: cp.started}
+	if cp.file, err = os.Create(path); err != nil {
+		return nil, err
+	}
+	if err := cp.write(cp.header); err != nil {
+		cp.file.Close()
+		return nil, err
+	}
+	return cp, nil
+}
+
+// Resumed 是否从之前的检查点继续
+func (c *Checkpoint) Resumed() bool {
+	return c.resumed
+}
+
+// Pending 返回尚未处理的文件（保持原顺序），并恢复它们的源目录去重结果
+// 组内已处理的文件都没有导入时（例如代表没有日期），由第一个未处理的文件代替代表
+func (c *Checkpoint) Pending(files []*FileInfo) []*FileInfo {
+	kept := make(map[string]bool) // 内容已在目标库中的重复组（以代表为键）
+	for path, entry := range c.done {
+		representative := c.deduped[path]
+		if representative == "" {
+			representative = path
+		}
+		if keepsContent(&ProcessRecord{Result: entry.Result, DuplicateOf: entry.DuplicateOf}) {
+			kept[representative] = true
+		}
+	}
+
+	promoted := make(map[string]string) // 代表 → 代替它导入的文件
+	pending := make([]*FileInfo, 0, len(files))
+	for _, file := range files {
+		if _, ok := c.done[file.Path]; ok {
+			continue
+		}
+		if representative := c.deduped[file.Path]; representative != "" {
+			if _, done := c.done[representative]; done && !kept[representative] {
+				if replacement, ok := promoted[representative]; ok {
+					representative = replacement
+				} else {
+					promoted[representative] = file.Path
+					representative = ""
+				}
+			}
+			file.SourceDuplicateOf = representative
+		}
+		pending = append(pending, file)
+	}
+	return pending
+}
+
+// SourceDeduped 检查点中是否已有源目录去重结果
+// 续传时直接使用记录的结果，不再读取源文件，移动模式下已移走的代表也不会改变结果
+func (c *Checkpoint) SourceDeduped() bool {
+	return c.deduped != nil
+}
+
+// RecordSourceDuplicates 记录源目录去重的结果（见 DedupeSource）
+func (c *Checkpoint) RecordSourceDuplicates(files []*FileInfo) error {
+	deduped := make(map[string]string)
+	for _, file := range files {
+		if file.SourceDuplicateOf != "" {
+			deduped[file.Path] = file.SourceDuplicateOf
+		}
+	}
+	if err := c.write(checkpointDedupe{SourceDuplicates: deduped}); err != nil {
+		return err
+	}
+	c.deduped = deduped
+	return nil
+}
+
+// Previous 由检查点重建之前运行的统计
+func (c *Checkpoint) Previous() *Statistics {
+	stats := &Statistics{StartTime: c.header.StartTime, Duration: c.elapsed, HashTiers: c.tiers}
+	for _, entry := range c.done {
+		stats.TotalFiles++
+		stats.ProcessedFiles++
+		switch entry.Type {
+		case FileTypePhoto:
+			stats.PhotoCount++
+		case FileTypeVideo:
+			stats.VideoCount++
+		}
+		stats.AddResult(&ProcessRecord{File: &FileInfo{Type: entry.Type}, Result: entry.Result})
+		stats.AddDateSource(entry.DateSource)
+	}
+	return stats
+}
+
+// Restore 恢复处理器的序号计数，避免续传后重复使用已分配的序号
+func (c *Checkpoint) Restore(p *Processor) {
+	for _, entry := range c.done {
+		if entry.SeqKey != "" && p.sequences[entry.SeqKey] < entry.Seq {
+			p.sequences[entry.SeqKey] = entry.Seq
+		}
+	}
+}
+
+// Record 记录一个已处理的文件
+func (c *Checkpoint) Record(record *ProcessRecord) error {
+	file := record.File
+	tiers := c.tiers
+	tiers.Add(file.tiers)
+	return c.write(checkpointEntry{
+		Path:        file.Path,
+		Size:        file.Size,
+		Type:        file.Type,
+		Result:      record.Result,
+		DuplicateOf: record.DuplicateOf,
+		DateSource:  file.DateSource,
+		SeqKey:      file.seqKey,
+		Seq:         file.seq,
+		Elapsed:     c.elapsed + time.Since(c.started),
+		Tiers:       tiers,
+	})
+}
+
+// Close 关闭检查点文件并保留，以便之后续传
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
+
+// Remove 运行完成后删除检查点
+func (c *Checkpoint) Remove() error {
+	c.file.Close()
+	return os.Remove(c.path)
+}
+
+// write 追加一行
+func (c *Checkpoint) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = c.file.Write(append(data, '\n'))
+	return err
+}
+
+// readCheckpoint 读取检查点，忽略中断时未写完的最后一行
+// 返回的去重结果为 nil 表示中断时尚未完成源目录去重
+func readCheckpoint(path string) (checkpointHeader, []checkpointEntry, map[string]string, error) {
+	var header checkpointHeader
+	file, err := os.Open(path)
+	if err != nil {
+		return header, nil, nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return header, nil, nil, fmt.Errorf("检查点文件为空")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return header, nil, nil, err
+	}
+	if header.Version != checkpointVersion {
+		return header, nil, nil, fmt.Errorf("不支持的检查点版本: %d", header.Version)
+	}
+
+	var entries []checkpointEntry
+	var deduped map[string]string
+	for scanner.Scan() {
+		var line struct {
+			checkpointEntry
+			checkpointDedupe
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			break
+		}
+		if line.SourceDuplicates != nil {
+			deduped = line.SourceDuplicates
+			continue
+		}
+		entries = append(entries, line.checkpointEntry)
+	}
+	return header, entries, deduped, nil
+}
+
+// checkpointPath 返回配置对应的检查点路径
+// 只影响结果的配置参与计算，运行模式、日志级别、并发数等不影响
+func checkpointPath(cfg *config.Config) (string, error) {
+	key := *cfg
+	key.Mode, key.ConfigFile, key.LogLevel, key.Resume, key.Workers = "", "", "", false, 0
+	if abs, err := filepath.Abs(key.SourceDir); err == nil {
+		key.SourceDir = abs
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%x.jsonl", sha256.Sum256(data))
+	return filepath.Join(cacheDir(), "checkpoints", name), nil
+}
+
+// treeHash 计算源文件列表的哈希
+// 已处理的文件也计入（移动模式下它们已不在源目录中），因此只有源目录的外部变化会改变结果
+func treeHash(files []*FileInfo, done []checkpointEntry) string {
+	sizes := make(map[string]int64, len(files)+len(done))
+	for _, file := range files {
+		sizes[file.Path] = file.Size
+	}
+	for _, entry := range done {
+		sizes[entry.Path] = entry.Size
+	}
+
+	paths := make([]string, 0, len(sizes))
+	for path := range sizes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	hash := sha256.New()
+	for _, path := range paths {
+		fmt.Fprintf(hash, "%s\x00%d\n", path, sizes[path])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
@@ -0,0 +1,216 @@
+package organizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	os.MkdirAll(source, 0755)
+	for _, name := range []string{"IMG_20210304_101530.jpg", "IMG_20210304_101530_2.jpg", "IMG_20210305_080000.jpg"} {
+		os.WriteFile(filepath.Join(source, name), []byte(name), 0644)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.SourceDir = source
+	cfg.TargetDir = filepath.Join(dir, "target")
+	cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+	cfg.RenamePattern = "{date:20060102_150405}_{seq:02}{ext}"
+	cfg.TransferMode = config.TransferMove
+	// 目标库索引模式下每个文件都会分级比较，检查点需要保存计数
+	cfg.DuplicateDetection = config.DetectionLibrary
+
+	files, _ := NewScanner(source).Scan(context.Background())
+	cp, err := OpenCheckpoint(cfg, files, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+
+	// 处理前两个文件后中断
+	processor := NewProcessor(cfg)
+	for _, file := range files[:2] {
+		record, err := processor.Process(context.Background(), file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cp.Record(record)
+	}
+	cp.Close()
+
+	// 移动模式下已处理的文件不在源目录中，仍可以续传
+	files, _ = NewScanner(source).Scan(context.Background())
+	resumed, err := OpenCheckpoint(cfg, files, true)
+	if err != nil || !resumed.Resumed() {
+		t.Fatalf("OpenCheckpoint(resume) = %v, resumed %v", err, resumed != nil && resumed.Resumed())
+	}
+	pending := resumed.Pending(files)
+	if len(pending) != 1 || pending[0].Name != "IMG_20210305_080000.jpg" {
+		t.Fatalf("Pending() = %v, want the third file", pending)
+	}
+	previous := resumed.Previous()
+	if previous.ProcessedFiles != 2 || previous.PhotoCount != 2 || previous.DateSourceCounts[DateSourceFilename] != 2 {
+		t.Errorf("Previous() = %+v", previous)
+	}
+	if tiers := processor.HashTiers(); tiers.Total() == 0 || previous.HashTiers != tiers {
+		t.Errorf("Previous() hash tiers = %+v, want %+v", previous.HashTiers, tiers)
+	}
+
+	// 续传后序号不会重复使用
+	processor = NewProcessor(cfg)
+	resumed.Restore(processor)
+	again := &FileInfo{Path: filepath.Join(dir, "IMG_20210304_101530.jpg"), Name: "IMG_20210304_101530.jpg", Type: FileTypePhoto}
+	os.WriteFile(again.Path, []byte("other"), 0644)
+	record, _ := processor.Process(context.Background(), again)
+	if got := filepath.Base(record.File.TargetPath); got != "20210304_101530_03.jpg" {
+		t.Errorf("target after resume = %s, want 20210304_101530_03.jpg", got)
+	}
+	resumed.Remove()
+
+	// 没有检查点或源目录变化时从头开始
+	files, _ = NewScanner(source).Scan(context.Background())
+	fresh, err := OpenCheckpoint(cfg, files, true)
+	if err != nil || fresh.Resumed() {
+		t.Errorf("OpenCheckpoint() after Remove resumed = %v, %v", fresh.Resumed(), err)
+	}
+	fresh.Close()
+	os.WriteFile(filepath.Join(source, "IMG_20210306_080000.jpg"), []byte("new"), 0644)
+	files, _ = NewScanner(source).Scan(context.Background())
+	changed, _ := OpenCheckpoint(cfg, files, true)
+	if changed.Resumed() {
+		t.Errorf("OpenCheckpoint() resumed after the source tree changed")
+	}
+	changed.Remove()
+}
+
+func TestCheckpointSourceDuplicates(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	os.MkdirAll(source, 0755)
+	for _, name := range []string{"IMG_20210304_101530.jpg", "IMG_20210304_101531.jpg", "IMG_20210305_080000.jpg"} {
+		os.WriteFile(filepath.Join(source, name), []byte("same"), 0644)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.SourceDir = source
+	cfg.TargetDir = filepath.Join(dir, "target")
+	cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+	cfg.TransferMode = config.TransferMove
+
+	files, _ := NewScanner(source).Scan(context.Background())
+	cp, err := OpenCheckpoint(cfg, files, false)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	if cp.SourceDeduped() {
+		t.Errorf("SourceDeduped() = true before dedupe")
+	}
+	processor := NewProcessor(cfg)
+	if _, err := processor.DedupeSource(context.Background(), files); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.RecordSourceDuplicates(files); err != nil {
+		t.Fatalf("RecordSourceDuplicates() error = %v", err)
+	}
+
+	// 移走代表后中断
+	record, err := processor.Process(context.Background(), files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Record(record)
+	cp.Close()
+
+	// 续传时恢复记录的去重结果，不再重新计算
+	files, _ = NewScanner(source).Scan(context.Background())
+	resumed, err := OpenCheckpoint(cfg, files, true)
+	if err != nil || !resumed.Resumed() || !resumed.SourceDeduped() {
+		t.Fatalf("OpenCheckpoint(resume) = %v, resumed %v", err, resumed != nil && resumed.Resumed())
+	}
+	representative := filepath.Join(source, "IMG_20210304_101530.jpg")
+	pending := resumed.Pending(files)
+	if len(pending) != 2 {
+		t.Fatalf("Pending() = %v, want two files", pending)
+	}
+	for _, file := range pending {
+		if file.SourceDuplicateOf != representative {
+			t.Errorf("%s SourceDuplicateOf = %q, want %q", file.Name, file.SourceDuplicateOf, representative)
+		}
+	}
+	resumed.Remove()
+}
+
+func TestCheckpointPendingReplacesRepresentative(t *testing.T) {
+	// 代表在之前的运行中因没有日期被跳过，续传时由第一个未处理的成员代替
+	cp := &Checkpoint{
+		done: map[string]checkpointEntry{
+			"a.jpg": {Path: "a.jpg", Result: ResultSkipped},
+			"x.jpg": {Path: "x.jpg", Result: ResultSuccess},
+		},
+		deduped: map[string]string{"b.jpg": "a.jpg", "c.jpg": "a.jpg", "y.jpg": "x.jpg"},
+	}
+	files := []*FileInfo{{Path: "a.jpg"}, {Path: "b.jpg"}, {Path: "c.jpg"}, {Path: "x.jpg"}, {Path: "y.jpg"}}
+
+	want := map[string]string{"b.jpg": "", "c.jpg": "b.jpg", "y.jpg": "x.jpg"}
+	pending := cp.Pending(files)
+	if len(pending) != len(want) {
+		t.Fatalf("Pending() = %d files, want %d", len(pending), len(want))
+	}
+	for _, file := range pending {
+		if file.SourceDuplicateOf != want[file.Path] {
+			t.Errorf("%s SourceDuplicateOf = %q, want %q", file.Path, file.SourceDuplicateOf, want[file.Path])
+		}
+	}
+}
+
+func TestStatisticsMerge(t *testing.T) {
+	stats := &Statistics{TotalFiles: 3, ProcessedFiles: 3, PhotoCount: 2, VideoCount: 1, SkippedCount: 1, Duration: 2,
+		HashTiers: HashTiers{Size: 2, Full: 1}}
+	stats.AddDateSource(DateSourceEXIF)
+	previous := &Statistics{TotalFiles: 5, ProcessedFiles: 5, PhotoCount: 5, FailedCount: 1, Duration: 3,
+		HashTiers: HashTiers{Size: 1, Partial: 2}}
+	previous.AddDateSource(DateSourceEXIF)
+	previous.AddDateSource(DateSourceMtime)
+
+	stats.Merge(previous)
+	if stats.TotalFiles != 8 || stats.ProcessedFiles != 8 || stats.PhotoCount != 7 || stats.VideoCount != 1 ||
+		stats.SkippedCount != 1 || stats.FailedCount != 1 || stats.Duration != 5 {
+		t.Errorf("Merge() = %+v", stats)
+	}
+	if want := (HashTiers{Size: 3, Partial: 2, Full: 1}); stats.HashTiers != want {
+		t.Errorf("Merge() hash tiers = %+v, want %+v", stats.HashTiers, want)
+	}
+	if stats.DateSourceCounts[DateSourceEXIF] != 2 || stats.DateSourceCounts[DateSourceMtime] != 1 {
+		t.Errorf("Merge() date sources = %v", stats.DateSourceCounts)
+	}
+}
+
+func TestStatisticsAddResult(t *testing.T) {
+	// 目标目录的文件数只计入成功的文件，跳过和失败的不计入
+	stats := &Statistics{}
+	for _, record := range []ProcessRecord{
+		{File: &FileInfo{Type: FileTypePhoto}, Result: ResultSuccess},
+		{File: &FileInfo{Type: FileTypePhoto}, Result: ResultSkipped},
+		{File: &FileInfo{Type: FileTypeVideo}, Result: ResultFailed},
+		{File: &FileInfo{Type: FileTypeVideo}, Result: ResultSuccess},
+	} {
+		stats.AddResult(&record)
+	}
+	previous := &Statistics{}
+	previous.AddResult(&ProcessRecord{File: &FileInfo{Type: FileTypePhoto}, Result: ResultSuccess})
+
+	stats.Merge(previous)
+	if stats.WrittenCounts[FileTypePhoto] != 2 || stats.WrittenCounts[FileTypeVideo] != 1 {
+		t.Errorf("AddResult() written = %v, want photo 2, video 1", stats.WrittenCounts)
+	}
+	if stats.SkippedCount != 1 || stats.FailedCount != 1 {
+		t.Errorf("AddResult() skipped = %d, failed = %d, want 1, 1", stats.SkippedCount, stats.FailedCount)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package organizer
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isCrossDevice 判断重命名是否因源和目标位于不同设备而失败
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
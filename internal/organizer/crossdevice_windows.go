@@ -0,0 +1,14 @@
+//go:build windows
+
+package organizer
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// isCrossDevice 判断重命名是否因源和目标位于不同卷而失败
+func isCrossDevice(err error) bool {
+	return errors.Is(err, windows.ERROR_NOT_SAME_DEVICE)
+}
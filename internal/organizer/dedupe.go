@@ -0,0 +1,66 @@
+package organizer
+
+import "context"
+
+// DedupeSource 找出源文件中内容相同的文件，每组只保留扫描顺序中的第一个作为代表导入
+// 依次按大小、首尾部分哈希、完整哈希分组，前一级已唯一的文件不再读取；
+// 其余文件的 SourceDuplicateOf 设为代表的路径，处理时记录为跳过（代表没有导入时由下一个文件代替，见 ProcessAll）。
+// 分级比较计数（HashTiers）只统计与目标的比较，不包括这里的源目录比较。
+// 返回重复文件数，ctx 取消时返回 ctx.Err()
+func (p *Processor) DedupeSource(ctx context.Context, files []*FileInfo) (int, error) {
+	hasher := p.Hasher()
+
+	bySize := make(map[int64][]*FileInfo)
+	for _, file := range files {
+		bySize[file.Size] = append(bySize[file.Size], file)
+	}
+
+	duplicates := 0
+	for _, file := range files {
+		group := bySize[file.Size]
+		if len(group) == 1 {
+			continue
+		}
+		// 每个大小分组只处理一次（在遇到分组的第一个文件时）
+		if group[0] != file {
+			continue
+		}
+
+		// 按部分哈希分组，无法读取的文件留给处理阶段报告错误
+		byPartial := make(map[Digest][]*FileInfo)
+		var readable []*FileInfo
+		for _, member := range group {
+			hash, err := member.partialHash(ctx, file.Size, hasher)
+			if ctx.Err() != nil {
+				return duplicates, ctx.Err()
+			}
+			if err == nil {
+				byPartial[hash] = append(byPartial[hash], member)
+				readable = append(readable, member)
+			}
+		}
+
+		// 部分哈希相同的文件再比较完整哈希（较小的文件部分哈希就是完整哈希）
+		first := make(map[Digest]*FileInfo)
+		for _, member := range readable {
+			if len(byPartial[member.partial]) == 1 {
+				continue
+			}
+
+			hash, err := member.fullHash(ctx, hasher)
+			if ctx.Err() != nil {
+				return duplicates, ctx.Err()
+			}
+			if err != nil {
+				continue
+			}
+			if representative, ok := first[hash]; ok {
+				member.SourceDuplicateOf = representative.Path
+				duplicates++
+				continue
+			}
+			first[hash] = member
+		}
+	}
+	return duplicates, nil
+}
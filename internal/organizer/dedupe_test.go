@@ -0,0 +1,120 @@
+package organizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestDedupeSource(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	for path, content := range map[string]string{
+		"backup1/IMG_20210304_101530.jpg": "same photo",
+		"backup2/IMG_20210304_101530.jpg": "same photo",
+		"backup3/renamed.jpg":             "same photo",
+		"other/IMG_20210305_101530.jpg":   "diff photo", // 大小相同但内容不同
+		"other/IMG_20210306_101530.jpg":   "unique size",
+	} {
+		path = filepath.Join(source, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = filepath.Join(dir, "target")
+	cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename, config.DateSourceMtime}}
+	cfg.DuplicateStrategy = config.StrategyRename
+	processor := NewProcessor(cfg)
+
+	files, _ := NewScanner(source).Scan(context.Background())
+	duplicates, err := processor.DedupeSource(context.Background(), files)
+	if err != nil {
+		t.Fatalf("DedupeSource() error = %v", err)
+	}
+	if duplicates != 2 {
+		t.Errorf("DedupeSource() = %d, want 2", duplicates)
+	}
+	// 源目录比较不计入与目标比较的分级计数
+	if tiers := processor.HashTiers(); tiers.Total() != 0 {
+		t.Errorf("HashTiers() = %+v, want none", tiers)
+	}
+
+	representative := filepath.Join(source, "backup1", "IMG_20210304_101530.jpg")
+	want := map[string]string{
+		"backup1/IMG_20210304_101530.jpg": "",
+		"backup2/IMG_20210304_101530.jpg": representative,
+		"backup3/renamed.jpg":             representative,
+		"other/IMG_20210305_101530.jpg":   "",
+		"other/IMG_20210306_101530.jpg":   "",
+	}
+	for _, file := range files {
+		rel, _ := filepath.Rel(source, file.Path)
+		if got := file.SourceDuplicateOf; got != want[filepath.ToSlash(rel)] {
+			t.Errorf("%s: SourceDuplicateOf = %q, want %q", rel, got, want[filepath.ToSlash(rel)])
+		}
+	}
+
+	// 重命名策略下重复文件也不会以 (1)、(2) 导入
+	imported := 0
+	for _, file := range files {
+		record, err := processor.Process(context.Background(), file)
+		if err != nil {
+			t.Fatalf("Process(%s) error = %v", file.Path, err)
+		}
+		switch {
+		case record.Result == ResultSuccess:
+			imported++
+		case record.Result != ResultSkipped || record.DuplicateOf != representative:
+			t.Errorf("%s: result = %s, duplicateOf = %q", file.Path, record.Result, record.DuplicateOf)
+		}
+	}
+	if imported != 3 {
+		t.Errorf("imported %d files, want 3", imported)
+	}
+}
+
+func TestDedupeSourceRepresentativeNotImported(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	for _, path := range []string{"a/IMG_0001.jpg", "b/IMG_20210304_101530.jpg", "c/IMG_20210305_101530.jpg"} {
+		path = filepath.Join(source, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("same photo"), 0644)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = filepath.Join(dir, "target")
+	// 代表（扫描顺序中的第一个）没有可用的日期
+	cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+	processor := NewProcessor(cfg)
+
+	files, _ := NewScanner(source).Scan(context.Background())
+	if duplicates, err := processor.DedupeSource(context.Background(), files); err != nil || duplicates != 2 {
+		t.Fatalf("DedupeSource() = %d, %v, want 2", duplicates, err)
+	}
+
+	// 代表被跳过后由下一个文件代替导入，其余文件仍然跳过
+	replacement := filepath.Join(source, "b", "IMG_20210304_101530.jpg")
+	want := []struct {
+		result      ProcessResult
+		duplicateOf string
+	}{
+		{ResultSkipped, ""},
+		{ResultSuccess, ""},
+		{ResultSkipped, replacement},
+	}
+	for result := range processor.ProcessAll(context.Background(), files, 2) {
+		record := result.Record
+		if record == nil {
+			t.Fatalf("%s: no record, err = %v", files[result.Index].Path, result.Err)
+		}
+		if w := want[result.Index]; record.Result != w.result || record.DuplicateOf != w.duplicateOf {
+			t.Errorf("%s: result = %s, duplicateOf = %q, want %s, %q",
+				record.File.Path, record.Result, record.DuplicateOf, w.result, w.duplicateOf)
+		}
+	}
+}
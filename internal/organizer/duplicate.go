@@ -1,6 +1,7 @@
 package organizer
 
 import (
+	"context"
 	"os"
 
 	"github.com/chiyiangel/media-organizer-v2/internal/config"
@@ -8,53 +9,131 @@ import (
 
 // DuplicateDetector 重复文件检测器
 type DuplicateDetector struct {
-	config *config.Config
+	config  *config.Config
+	hasher  Hasher        // 内容摘要算法
+	library *LibraryIndex // 目标库内容索引，仅内容索引模式使用
+	similar *SimilarIndex // 目标库照片的感知哈希索引，仅感知哈希模式使用
+	tiers   HashTiers     // 分级比较的计数（只在分配阶段按顺序更新）
 }
 
 // NewDuplicateDetector 创建检测器
 func NewDuplicateDetector(cfg *config.Config) *DuplicateDetector {
-	return &DuplicateDetector{
+	// 哈希算法已在配置校验时检查，无效时使用 md5
+	hasher, err := HasherFor(cfg.DigestAlgorithm())
+	if err != nil {
+		hasher = hashers["md5"]
+	}
+	d := &DuplicateDetector{
 		config: cfg,
+		hasher: hasher,
+	}
+	if cfg.DuplicateDetection == config.DetectionLibrary {
+		d.library = NewLibraryIndex(hasher, cfg.TargetDirFor(string(FileTypePhoto)), cfg.TargetDirFor(string(FileTypeVideo)))
+	}
+	if cfg.DuplicateDetection == config.DetectionPerceptual {
+		d.similar = NewSimilarIndex(cfg.SimilarityThreshold(), cfg.TargetDirFor(string(FileTypePhoto)))
 	}
+	return d
 }
 
 // IsDuplicate 检查是否重复
-func (d *DuplicateDetector) IsDuplicate(file *FileInfo) (bool, error) {
+func (d *DuplicateDetector) IsDuplicate(ctx context.Context, file *FileInfo) (bool, error) {
 	// 检查目标文件是否存在
 	if _, err := os.Stat(file.TargetPath); os.IsNotExist(err) {
 		return false, nil
 	}
 
+	return d.IsDuplicateOf(ctx, file, file.TargetPath)
+}
+
+// IsDuplicateOf 检查文件是否与占用同一目标路径的已有文件重复
+// existing 可以是目标文件，也可以是本次运行中计划写入该路径的源文件
+func (d *DuplicateDetector) IsDuplicateOf(ctx context.Context, file *FileInfo, existing string) (bool, error) {
 	switch d.config.DuplicateDetection {
 	case config.DetectionFilename:
 		// 文件名模式：文件存在即为重复
 		return true, nil
 
-	case config.DetectionMD5:
-		// MD5模式：比较文件内容
-		return d.compareByMD5(file)
-
 	default:
-		return false, nil
+		// 内容哈希、内容索引和感知哈希模式：分级比较文件内容
+		return d.compareContent(ctx, file, existing)
 	}
 }
 
-// compareByMD5 通过MD5比较
-func (d *DuplicateDetector) compareByMD5(file *FileInfo) (bool, error) {
-	// 计算源文件MD5
-	if file.MD5 == "" {
-		md5, err := CalculateMD5(file.Path)
-		if err != nil {
-			return false, err
-		}
-		file.MD5 = md5
+// FindInLibrary 在整个目标库中查找内容相同的文件，返回其位置
+// 不是内容索引模式或没有找到时返回空字符串
+func (d *DuplicateDetector) FindInLibrary(ctx context.Context, file *FileInfo) (string, error) {
+	if d.library == nil {
+		return "", nil
 	}
+	return d.library.Find(ctx, file, &d.tiers)
+}
+
+// AddToLibrary 将计划写入的文件加入目标库索引，之后的文件也会与它比较
+func (d *DuplicateDetector) AddToLibrary(file *FileInfo) {
+	if d.library != nil {
+		d.library.Add(file)
+	}
+	if d.similar != nil && file.perceptual != nil {
+		d.similar.Add(file.TargetPath, *file.perceptual)
+	}
+}
 
-	// 计算目标文件MD5
-	targetMD5, err := CalculateMD5(file.TargetPath)
+// PreparePerceptual 感知哈希模式下解码照片并计算感知哈希
+// 只读取源文件，在准备阶段并发执行；无法解码的照片只按内容比较，ctx 取消时返回 ctx.Err()
+func (d *DuplicateDetector) PreparePerceptual(ctx context.Context, file *FileInfo) error {
+	if d.similar == nil || file.Type != FileTypePhoto || !supportsPerceptual(file.Path) {
+		return nil
+	}
+	info, err := perceptualHash(ctx, file.Path)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == nil {
+		file.perceptual = &info
+	}
+	return nil
+}
+
+// FindSimilar 在目标库中查找与照片相似的照片
+// 不是感知哈希模式、照片无法解码或没有找到时返回 nil
+func (d *DuplicateDetector) FindSimilar(ctx context.Context, file *FileInfo) (*SimilarMatch, error) {
+	if d.similar == nil || file.perceptual == nil {
+		return nil, nil
+	}
+	return d.similar.Find(ctx, *file.perceptual)
+}
+
+// KeepsNew 保留分辨率较高的版本时，判断是否用新照片替换相似的已有照片
+// 新照片像素更多时返回 true，已有照片移出索引，之后的照片与新照片比较
+func (d *DuplicateDetector) KeepsNew(file *FileInfo, match *SimilarMatch) bool {
+	if file.perceptual == nil || file.perceptual.pixels <= match.Pixels {
+		return false
+	}
+	match.entry.replaced = true
+	return true
+}
+
+// Tiers 返回分级比较的计数
+func (d *DuplicateDetector) Tiers() HashTiers {
+	return d.tiers
+}
+
+// compareContent 分级比较文件内容：大小不同时不读取文件，
+// 其次比较首尾部分哈希，只有部分哈希相同时才计算完整哈希
+func (d *DuplicateDetector) compareContent(ctx context.Context, file *FileInfo, existing string) (bool, error) {
+	srcInfo, err := os.Stat(file.Path)
 	if err != nil {
 		return false, err
 	}
+	dstInfo, err := os.Stat(existing)
+	if err != nil {
+		return false, err
+	}
+	if srcInfo.Size() != dstInfo.Size() {
+		d.tiers.Size++
+		return false, nil
+	}
 
-	return file.MD5 == targetMD5, nil
+	return sameContent(ctx, file, &FileInfo{Path: existing}, srcInfo.Size(), d.hasher, &d.tiers)
 }
@@ -0,0 +1,90 @@
+package organizer
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// 文件名日期模式
+// 模式必须包含命名分组 year、month、day，可选 hour、minute、second
+
+// builtinFilenamePatterns 内置的常见命名规则
+var builtinFilenamePatterns = []string{
+	// Signal: signal-2021-03-04-101530.jpg, signal-2021-03-04-10-15-30-123.jpg
+	`signal-(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})-(?P<hour>\d{2})-?(?P<minute>\d{2})-?(?P<second>\d{2})`,
+	// WhatsApp: IMG-20210304-WA0001.jpg, VID-20190102-WA0003.mp4
+	`(?:IMG|VID|AUD|PTT|STK)-(?P<year>\d{4})(?P<month>\d{2})(?P<day>\d{2})-WA\d+`,
+	// 相机/手机/Pixel: IMG_20210304_101530.jpg, PXL_20230101_123456789.jpg, Screenshot_20220506-101530.png
+	`(?:^|\D)(?P<year>(?:19|20)\d{2})(?P<month>\d{2})(?P<day>\d{2})[_-](?P<hour>\d{2})(?P<minute>\d{2})(?P<second>\d{2})`,
+	// 截图等: Screenshot_2022-05-06-10-15-30-123_com.app.png, 2021-03-04 10.15.30.jpg
+	`(?:^|\D)(?P<year>(?:19|20)\d{2})-(?P<month>\d{2})-(?P<day>\d{2})[-_ ](?P<hour>\d{2})[-.:](?P<minute>\d{2})[-.:](?P<second>\d{2})`,
+	// 仅日期: 2021-03-04.jpg, 20210304.jpg
+	`(?:^|\D)(?P<year>(?:19|20)\d{2})-(?P<month>\d{2})-(?P<day>\d{2})(?:\D|$)`,
+	`(?:^|\D)(?P<year>(?:19|20)\d{2})(?P<month>\d{2})(?P<day>\d{2})(?:\D|$)`,
+}
+
+// filenameDateParser 文件名日期解析器
+type filenameDateParser struct {
+	patterns []*regexp.Regexp
+}
+
+// newFilenameDateParser 创建解析器，用户模式优先于内置模式
+// 无法编译的模式会被忽略（配置加载时已校验）
+func newFilenameDateParser(extra []string) *filenameDateParser {
+	p := &filenameDateParser{}
+	for _, expr := range append(append([]string{}, extra...), builtinFilenamePatterns...) {
+		if re, err := regexp.Compile(expr); err == nil {
+			p.patterns = append(p.patterns, re)
+		}
+	}
+	return p
+}
+
+// Parse 从文件名中解析日期（本地时间）
+func (p *filenameDateParser) Parse(name string) (time.Time, bool) {
+	for _, re := range p.patterns {
+		match := re.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		parts := map[string]int{}
+		for i, group := range re.SubexpNames() {
+			if group == "" || match[i] == "" {
+				continue
+			}
+			if v, err := strconv.Atoi(match[i]); err == nil {
+				parts[group] = v
+			}
+		}
+
+		if t, ok := buildFilenameDate(parts); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// buildFilenameDate 校验并组装日期
+func buildFilenameDate(parts map[string]int) (time.Time, bool) {
+	year, hasYear := parts["year"]
+	month, hasMonth := parts["month"]
+	day, hasDay := parts["day"]
+	if !hasYear || !hasMonth || !hasDay {
+		return time.Time{}, false
+	}
+	hour, minute, second := parts["hour"], parts["minute"], parts["second"]
+
+	if month < 1 || month > 12 || day < 1 || day > 31 ||
+		hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, time.Local)
+	// 排除 02-30 等会被自动进位的日期
+	if t.Day() != day || int(t.Month()) != month {
+		return time.Time{}, false
+	}
+	return t, true
+}
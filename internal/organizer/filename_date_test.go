@@ -0,0 +1,37 @@
+package organizer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilenameDateParser(t *testing.T) {
+	parser := newFilenameDateParser([]string{`^trip_(?P<day>\d{2})\.(?P<month>\d{2})\.(?P<year>\d{4})`})
+
+	tests := []struct {
+		name     string
+		filename string
+		expected time.Time
+		ok       bool
+	}{
+		{"Camera", "IMG_20210304_101530.jpg", time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local), true},
+		{"WhatsApp", "VID-20190102-WA0003.mp4", time.Date(2019, 1, 2, 0, 0, 0, 0, time.Local), true},
+		{"Screenshot", "Screenshot_2022-05-06-10-15-30-123_com.app.png", time.Date(2022, 5, 6, 10, 15, 30, 0, time.Local), true},
+		{"Pixel", "PXL_20230101_123456789.jpg", time.Date(2023, 1, 1, 12, 34, 56, 0, time.Local), true},
+		{"Signal", "signal-2021-03-04-101530.jpg", time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local), true},
+		{"Date only", "2020-12-31.png", time.Date(2020, 12, 31, 0, 0, 0, 0, time.Local), true},
+		{"User pattern", "trip_04.03.2021.jpg", time.Date(2021, 3, 4, 0, 0, 0, 0, time.Local), true},
+		{"Invalid date", "IMG_20210230_101530.jpg", time.Time{}, false},
+		{"No date", "DSC00012.ARW", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := parser.Parse(tt.filename)
+			if ok != tt.ok || !result.Equal(tt.expected) {
+				t.Errorf("Parse(%s) = %v, %v, want %v, %v",
+					tt.filename, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package organizer
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+)
+
+// Hasher 内容摘要算法
+type Hasher interface {
+	Name() string   // 算法名称，记录在摘要中
+	New() hash.Hash // 创建新的哈希计算
+}
+
+// stdHasher 基于标准库哈希实现的算法
+type stdHasher struct {
+	name string
+	new  func() hash.Hash
+}
+
+func (h stdHasher) Name() string   { return h.name }
+func (h stdHasher) New() hash.Hash { return h.new() }
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// hashers 支持的摘要算法
+var hashers = map[string]Hasher{
+	"md5":     stdHasher{"md5", md5.New},
+	"sha256":  stdHasher{"sha256", sha256.New},
+	"crc64":   stdHasher{"crc64", func() hash.Hash { return crc64.New(crc64Table) }},
+	"fnv128a": stdHasher{"fnv128a", fnv.New128a},
+}
+
+// HasherFor 按名称返回摘要算法
+func HasherFor(name string) (Hasher, error) {
+	hasher, ok := hashers[name]
+	if !ok {
+		return nil, fmt.Errorf("不支持的哈希算法: %s", name)
+	}
+	return hasher, nil
+}
+
+// Digest 记录了算法的内容摘要，格式为 "算法:十六进制值"，例如 "sha256:9f86..."
+// 旧版本操作日志和计划文件中只有十六进制值，视为 md5
+type Digest string
+
+// newDigest 由算法和哈希结果生成摘要
+func newDigest(hasher Hasher, sum []byte) Digest {
+	return Digest(fmt.Sprintf("%s:%x", hasher.Name(), sum))
+}
+
+// Algorithm 返回摘要的算法名称，空摘要返回空字符串
+func (d Digest) Algorithm() string {
+	if d == "" {
+		return ""
+	}
+	if alg, _, ok := strings.Cut(string(d), ":"); ok {
+		return alg
+	}
+	return "md5"
+}
+
+// Hex 返回摘要的十六进制值
+func (d Digest) Hex() string {
+	if _, hex, ok := strings.Cut(string(d), ":"); ok {
+		return hex
+	}
+	return string(d)
+}
+
+// hasher 返回计算该摘要使用的算法
+func (d Digest) hasher() (Hasher, error) {
+	return HasherFor(d.Algorithm())
+}
+
+// CalculateDigest 计算文件摘要，ctx 取消时在数据块边界停止
+func CalculateDigest(ctx context.Context, path string, hasher Hasher) (Digest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := hasher.New()
+	if _, err := io.Copy(hash, contextReader{ctx, file}); err != nil {
+		return "", err
+	}
+
+	return newDigest(hasher, hash.Sum(nil)), nil
+}
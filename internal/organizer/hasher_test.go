@@ -0,0 +1,64 @@
+package organizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestCalculateDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "IMG_0001.jpg")
+	os.WriteFile(path, []byte("abc"), 0644)
+
+	tests := []struct {
+		name      string
+		detection config.DuplicateDetection
+		algorithm string
+		want      Digest
+	}{
+		{"md5", config.DetectionHash, config.HashMD5, "md5:900150983cd24fb0d6963f7d28e17f72"},
+		{"sha256", config.DetectionHash, config.HashSHA256, "sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"crc64", config.DetectionHash, config.HashCRC64, "crc64:2cd8094a1a277627"},
+		{"fnv128a", config.DetectionHash, config.HashFNV128a, "fnv128a:a68d622cec8b5822836dbc7977af7f3b"},
+		// 哈希算法对所有识别策略生效，未设置时使用 md5
+		{"filename default", config.DetectionFilename, "", "md5:900150983cd24fb0d6963f7d28e17f72"},
+		{"library sha256", config.DetectionLibrary, config.HashSHA256, "sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"perceptual crc64", config.DetectionPerceptual, config.HashCRC64, "crc64:2cd8094a1a277627"},
+		// 旧版本按算法命名的策略
+		{"legacy fnv128a", config.DetectionFNV128a, "", "fnv128a:a68d622cec8b5822836dbc7977af7f3b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.DuplicateDetection = tt.detection
+			cfg.HashAlgorithm = tt.algorithm
+			hasher := NewProcessor(cfg).Hasher()
+
+			got, err := CalculateDigest(context.Background(), path, hasher)
+			if err != nil {
+				t.Fatalf("CalculateDigest() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CalculateDigest() = %q, want %q", got, tt.want)
+			}
+			if got.Algorithm() != hasher.Name() {
+				t.Errorf("Algorithm() = %q, want %q", got.Algorithm(), hasher.Name())
+			}
+		})
+	}
+}
+
+func TestDigestLegacy(t *testing.T) {
+	// 旧版本日志和计划文件只记录 MD5 的十六进制值
+	legacy := Digest("900150983cd24fb0d6963f7d28e17f72")
+	if legacy.Algorithm() != "md5" || legacy.Hex() != string(legacy) {
+		t.Errorf("legacy digest = %s:%s", legacy.Algorithm(), legacy.Hex())
+	}
+	if _, err := HasherFor("sha1"); err == nil {
+		t.Errorf("HasherFor(sha1) error = nil, want unsupported")
+	}
+}
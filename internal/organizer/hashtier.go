@@ -0,0 +1,124 @@
+package organizer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// partialHashChunk 部分哈希读取的首尾字节数
+const partialHashChunk = 1 << 20
+
+// HashTiers 分级比较内容时各级得出结论的次数
+// 依次比较大小、首尾部分哈希、完整哈希，前一级能区分时不再读取更多数据
+type HashTiers struct {
+	Size    int // 大小不同（或大小唯一），无需读取文件
+	Partial int // 首尾部分哈希即可判断（不超过两个数据块的文件部分哈希就是完整哈希）
+	Full    int // 需要计算完整哈希
+}
+
+// Total 比较总次数
+func (t HashTiers) Total() int {
+	return t.Size + t.Partial + t.Full
+}
+
+// Add 累加另一组计数
+func (t *HashTiers) Add(other HashTiers) {
+	t.Size += other.Size
+	t.Partial += other.Partial
+	t.Full += other.Full
+}
+
+// coveredByPartial 文件是否小到部分哈希已包含全部内容
+func coveredByPartial(size int64) bool {
+	return size <= 2*partialHashChunk
+}
+
+// partialDigest 计算文件大小及首尾各 partialHashChunk 字节的摘要
+// 较小的文件直接计算完整摘要，与 CalculateDigest 结果相同
+func partialDigest(ctx context.Context, path string, size int64, hasher Hasher) (Digest, error) {
+	if coveredByPartial(size) {
+		return CalculateDigest(ctx, path, hasher)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := hasher.New()
+	fmt.Fprintf(hash, "%d\n", size)
+	if _, err := io.Copy(hash, io.NewSectionReader(file, 0, partialHashChunk)); err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(hash, io.NewSectionReader(file, size-partialHashChunk, partialHashChunk)); err != nil {
+		return "", err
+	}
+	return newDigest(hasher, hash.Sum(nil)), nil
+}
+
+// partialHash 计算并缓存文件的部分摘要，较小的文件同时得到完整摘要
+// 缓存的摘要算法不同时重新计算
+func (f *FileInfo) partialHash(ctx context.Context, size int64, hasher Hasher) (Digest, error) {
+	if f.partial.Algorithm() == hasher.Name() {
+		return f.partial, nil
+	}
+	if coveredByPartial(size) && f.Digest.Algorithm() == hasher.Name() {
+		f.partial = f.Digest
+		return f.partial, nil
+	}
+
+	digest, err := partialDigest(ctx, f.Path, size, hasher)
+	if err != nil {
+		return "", err
+	}
+	f.partial = digest
+	if coveredByPartial(size) {
+		f.Digest = digest
+	}
+	return digest, nil
+}
+
+// fullHash 计算并缓存文件的完整摘要
+func (f *FileInfo) fullHash(ctx context.Context, hasher Hasher) (Digest, error) {
+	if f.Digest.Algorithm() != hasher.Name() {
+		digest, err := CalculateDigest(ctx, f.Path, hasher)
+		if err != nil {
+			return "", err
+		}
+		f.Digest = digest
+	}
+	return f.Digest, nil
+}
+
+// sameContent 分级比较两个大小相同的文件，返回是否相同以及得出结论的级别
+func sameContent(ctx context.Context, a, b *FileInfo, size int64, hasher Hasher, tiers *HashTiers) (bool, error) {
+	pa, err := a.partialHash(ctx, size, hasher)
+	if err != nil {
+		return false, err
+	}
+	pb, err := b.partialHash(ctx, size, hasher)
+	if err != nil {
+		return false, err
+	}
+	if pa != pb || coveredByPartial(size) {
+		tiers.Partial++
+		return pa == pb, nil
+	}
+
+	fa, err := a.fullHash(ctx, hasher)
+	if err != nil {
+		return false, err
+	}
+	fb, err := b.fullHash(ctx, hasher)
+	if err != nil {
+		return false, err
+	}
+	tiers.Full++
+	return fa == fb, nil
+}
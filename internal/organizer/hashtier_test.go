@@ -0,0 +1,62 @@
+package organizer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestCompareContentTiers(t *testing.T) {
+	dir := t.TempDir()
+	base := bytes.Repeat([]byte("0123456789abcdef"), 3*partialHashChunk/16)
+	changed := func(offset int) []byte {
+		data := append([]byte(nil), base...)
+		data[offset] ^= 0xff
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		existing []byte
+		want     bool
+		tiers    HashTiers
+	}{
+		{"identical", base, true, HashTiers{Full: 1}},
+		{"different size", base[:len(base)-1], false, HashTiers{Size: 1}},
+		{"different head", changed(0), false, HashTiers{Partial: 1}},
+		{"different tail", changed(len(base) - 1), false, HashTiers{Partial: 1}},
+		{"different middle", changed(len(base) / 2), false, HashTiers{Full: 1}},
+		{"small file", []byte("small"), true, HashTiers{Partial: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := filepath.Join(dir, tt.name+".src")
+			existing := filepath.Join(dir, tt.name+".dst")
+			content := base
+			if tt.name == "small file" {
+				content = tt.existing
+			}
+			os.WriteFile(src, content, 0644)
+			os.WriteFile(existing, tt.existing, 0644)
+
+			cfg := config.NewDefaultConfig()
+			cfg.DuplicateDetection = config.DetectionHash
+			detector := NewDuplicateDetector(cfg)
+			got, err := detector.IsDuplicateOf(context.Background(), &FileInfo{Path: src}, existing)
+			if err != nil {
+				t.Fatalf("IsDuplicateOf() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsDuplicateOf() = %v, want %v", got, tt.want)
+			}
+			if detector.Tiers() != tt.tiers {
+				t.Errorf("Tiers() = %+v, want %+v", detector.Tiers(), tt.tiers)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package organizer
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// HEIF/HEIC 中的 EXIF 数据以 "Exif" 类型的条目存储在顶层 meta 盒子中：
+//   meta/iinf 给出条目ID与类型，meta/iloc 给出条目在文件（或 meta/idat）中的位置
+// 条目内容: tiff_header_offset(4) + [偏移填充] + TIFF数据
+
+// heifBrands HEIF 系列的 ftyp 品牌
+var heifBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true,
+	"hevc": true, "hevx": true, "mif1": true, "msf1": true,
+	"avif": true, "avis": true,
+}
+
+// maxHEIFExifSize EXIF 条目最大长度
+const maxHEIFExifSize = 16 << 20
+
+var errNoHEIFExif = errors.New("HEIF文件中没有EXIF数据")
+
+// isHEIF 通过 ftyp 判断是否为 HEIF 文件
+func isHEIF(r io.ReaderAt) bool {
+	var hdr [12]byte
+	if _, err := r.ReadAt(hdr[:], 0); err != nil {
+		return false
+	}
+	if string(hdr[4:8]) != "ftyp" {
+		return false
+	}
+	return heifBrands[string(hdr[8:12])]
+}
+
+// heifExtent 条目数据区段
+type heifExtent struct {
+	offset int64
+	length int64
+}
+
+// heifLocation 条目位置
+type heifLocation struct {
+	constructionMethod int
+	baseOffset         int64
+	extents            []heifExtent
+}
+
+// readHEIFExif 读取 HEIF 文件中的 EXIF 条目，返回从 TIFF 头开始的数据
+func readHEIFExif(r io.ReaderAt, fileSize int64) ([]byte, error) {
+	top, _ := readBMFFBoxes(r, 0, fileSize)
+	meta, ok := findBMFFBox(top, "meta")
+	if !ok {
+		return nil, errNoHEIFExif
+	}
+
+	children, err := readMetaChildren(r, meta)
+	if err != nil && len(children) == 0 {
+		return nil, err
+	}
+
+	iinf, ok := findBMFFBox(children, "iinf")
+	if !ok {
+		return nil, errNoHEIFExif
+	}
+	itemID, ok := findHEIFExifItem(r, iinf)
+	if !ok {
+		return nil, errNoHEIFExif
+	}
+
+	iloc, ok := findBMFFBox(children, "iloc")
+	if !ok {
+		return nil, errNoHEIFExif
+	}
+	loc, ok, err := findHEIFItemLocation(r, iloc, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errNoHEIFExif
+	}
+
+	// 构造方式 1 表示偏移相对于 meta/idat
+	var base int64
+	switch loc.constructionMethod {
+	case 0:
+		base = 0
+	case 1:
+		idat, ok := findBMFFBox(children, "idat")
+		if !ok {
+			return nil, errNoHEIFExif
+		}
+		base = idat.Start
+	default:
+		return nil, errNoHEIFExif
+	}
+
+	var data []byte
+	for _, ext := range loc.extents {
+		length := ext.length
+		if length == 0 {
+			// 长度为0表示延伸到文件末尾
+			length = fileSize - (base + loc.baseOffset + ext.offset)
+		}
+		if length < 0 || int64(len(data))+length > maxHEIFExifSize {
+			return nil, errInvalidBox
+		}
+		buf := make([]byte, length)
+		if _, err := r.ReadAt(buf, base+loc.baseOffset+ext.offset); err != nil {
+			return nil, err
+		}
+		data = append(data, buf...)
+	}
+
+	// 跳过 TIFF 头偏移
+	if len(data) < 4 {
+		return nil, errNoHEIFExif
+	}
+	headerOffset := int64(binary.BigEndian.Uint32(data[:4]))
+	if 4+headerOffset >= int64(len(data)) {
+		return nil, errNoHEIFExif
+	}
+	return data[4+headerOffset:], nil
+}
+
+// findHEIFExifItem 在 iinf 中查找 Exif 条目的ID
+func findHEIFExifItem(r io.ReaderAt, iinf bmffBox) (uint32, bool) {
+	var hdr [4]byte
+	if _, err := r.ReadAt(hdr[:], iinf.Start); err != nil {
+		return 0, false
+	}
+
+	// 版本0使用16位条目数，其余使用32位
+	start := iinf.Start + 6
+	if hdr[0] != 0 {
+		start = iinf.Start + 8
+	}
+
+	entries, _ := readBMFFBoxes(r, start, iinf.End)
+	for _, entry := range entries {
+		if entry.Type != "infe" {
+			continue
+		}
+		payload, err := readBoxPayload(r, entry, 1<<16)
+		if err != nil {
+			continue
+		}
+		c := &byteCursor{buf: payload}
+		version := c.uint(1)
+		c.skip(3)
+
+		// 版本0/1的 infe 不包含条目类型
+		var id uint64
+		switch version {
+		case 2:
+			id = c.uint(2)
+		case 3:
+			id = c.uint(4)
+		default:
+			continue
+		}
+		c.skip(2) // item_protection_index
+		itemType := c.bytes(4)
+		if c.err == nil && string(itemType) == "Exif" {
+			return uint32(id), true
+		}
+	}
+
+	return 0, false
+}
+
+// findHEIFItemLocation 在 iloc 中查找指定条目的位置
+func findHEIFItemLocation(r io.ReaderAt, iloc bmffBox, itemID uint32) (heifLocation, bool, error) {
+	payload, err := readBoxPayload(r, iloc, 1<<20)
+	if err != nil {
+		return heifLocation{}, false, err
+	}
+
+	c := &byteCursor{buf: payload}
+	version := c.uint(1)
+	c.skip(3)
+
+	sizes := c.uint(1)
+	offsetSize := int(sizes >> 4)
+	lengthSize := int(sizes & 0x0f)
+	sizes = c.uint(1)
+	baseOffsetSize := int(sizes >> 4)
+	indexSize := 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0x0f)
+	}
+
+	var itemCount uint64
+	if version < 2 {
+		itemCount = c.uint(2)
+	} else {
+		itemCount = c.uint(4)
+	}
+
+	for i := uint64(0); i < itemCount && c.err == nil; i++ {
+		var loc heifLocation
+		var id uint64
+		if version < 2 {
+			id = c.uint(2)
+		} else {
+			id = c.uint(4)
+		}
+		if version == 1 || version == 2 {
+			loc.constructionMethod = int(c.uint(2) & 0x0f)
+		}
+		c.skip(2) // data_reference_index
+		loc.baseOffset = int64(c.uint(baseOffsetSize))
+
+		extentCount := c.uint(2)
+		for j := uint64(0); j < extentCount && c.err == nil; j++ {
+			if indexSize > 0 {
+				c.skip(indexSize)
+			}
+			loc.extents = append(loc.extents, heifExtent{
+				offset: int64(c.uint(offsetSize)),
+				length: int64(c.uint(lengthSize)),
+			})
+		}
+
+		if c.err == nil && uint32(id) == itemID {
+			return loc, true, nil
+		}
+	}
+
+	return heifLocation{}, false, c.err
+}
+
+// byteCursor 大端字节读取游标
+type byteCursor struct {
+	buf []byte
+	pos int
+	err error
+}
+
+// bytes 读取 n 个字节
+func (c *byteCursor) bytes(n int) []byte {
+	if c.err != nil {
+		return nil
+	}
+	if n < 0 || c.pos+n > len(c.buf) {
+		c.err = errInvalidBox
+		return nil
+	}
+	b := c.buf[c.pos : c.pos+n]
+	c.pos += n
+	return b
+}
+
+// skip 跳过 n 个字节
+func (c *byteCursor) skip(n int) {
+	c.bytes(n)
+}
+
+// uint 读取 n 字节（0/1/2/4/8）的无符号整数
+func (c *byteCursor) uint(n int) uint64 {
+	b := c.bytes(n)
+	if c.err != nil {
+		return 0
+	}
+	switch n {
+	case 0:
+		return 0
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b))
+	case 8:
+		return binary.BigEndian.Uint64(b)
+	default:
+		c.err = errInvalidBox
+		return 0
+	}
+}
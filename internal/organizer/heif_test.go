@@ -0,0 +1,92 @@
+package organizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+// testTIFF 构造只包含 DateTimeOriginal 的最小 TIFF
+func testTIFF(dateTime string) []byte {
+	value := append([]byte(dateTime), 0)
+	buf := []byte("II*\x00")
+	buf = binary.LittleEndian.AppendUint32(buf, 8)
+	buf = binary.LittleEndian.AppendUint16(buf, 1)
+	buf = binary.LittleEndian.AppendUint16(buf, 0x9003)
+	buf = binary.LittleEndian.AppendUint16(buf, 2)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(value)))
+	buf = binary.LittleEndian.AppendUint32(buf, 26)
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+	return append(buf, value...)
+}
+
+// testHEIF 构造包含 Exif 条目的最小 HEIF 文件
+func testHEIF(tiff []byte) []byte {
+	exifItem := append(testUint32(6), []byte("Exif\x00\x00")...)
+	exifItem = append(exifItem, tiff...)
+
+	infe := func(id uint16, typ string) []byte {
+		return testBox("infe", []byte{2, 0, 0, 0}, []byte{byte(id >> 8), byte(id)}, []byte{0, 0}, []byte(typ), []byte{0})
+	}
+	iinf := testBox("iinf", []byte{0, 0, 0, 0}, []byte{0, 2}, infe(1, "hvc1"), infe(2, "Exif"))
+
+	build := func(offset uint32) []byte {
+		iloc := testBox("iloc", []byte{0, 0, 0, 0}, []byte{0x44, 0x00}, []byte{0, 2},
+			[]byte{0, 1}, []byte{0, 0}, []byte{0, 1}, testUint32(offset), testUint32(4),
+			[]byte{0, 2}, []byte{0, 0}, []byte{0, 1}, testUint32(offset), testUint32(uint32(len(exifItem))),
+		)
+		meta := testBox("meta", []byte{0, 0, 0, 0}, testBox("hdlr", make([]byte, 24)), iinf, iloc)
+		return bytes.Join([][]byte{testBox("ftyp", []byte("heic"), testUint32(0), []byte("mif1heic")), meta}, nil)
+	}
+
+	// 先计算头部长度，再把条目数据放在 mdat 中
+	head := build(0)
+	offset := uint32(len(head) + 8)
+	return append(build(offset), testBox("mdat", exifItem)...)
+}
+
+func TestReadHEIFExif(t *testing.T) {
+	tiff := testTIFF("2021:03:04 10:15:30")
+	data := testHEIF(tiff)
+
+	if !isHEIF(bytes.NewReader(data)) {
+		t.Fatalf("isHEIF() = false, want true")
+	}
+
+	got, err := readHEIFExif(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("readHEIFExif() error = %v", err)
+	}
+	if !bytes.Equal(got, tiff) {
+		t.Errorf("readHEIFExif() = %q, want %q", got, tiff)
+	}
+}
+
+func TestExtractPhotoDateHEIC(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "IMG_0001.HEIC")
+	if err := os.WriteFile(path, testHEIF(testTIFF("2021:03:04 10:15:30")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &FileInfo{Path: path, Name: filepath.Base(path), Type: FileTypePhoto}
+	got, err := NewMetadataExtractor(config.NewDefaultConfig()).ExtractDate(file)
+	if err != nil {
+		t.Fatalf("ExtractDate() error = %v", err)
+	}
+	want := time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local)
+	if !got.Equal(want) || file.DateSource != DateSourceEXIF {
+		t.Errorf("ExtractDate() = %v, %v, want %v, %v", got, file.DateSource, want, DateSourceEXIF)
+	}
+}
+
+func TestReadHEIFExifMissing(t *testing.T) {
+	data := testBox("ftyp", []byte("heic"), testUint32(0))
+	if _, err := readHEIFExif(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Errorf("readHEIFExif() should fail without meta box")
+	}
+}
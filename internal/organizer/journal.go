@@ -0,0 +1,355 @@
+package organizer
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
+)
+
+// backupDirName 覆盖前备份原目标文件的目录（位于目标根目录下）
+const backupDirName = ".media-organizer-backup"
+
+// JournalOp 日志中记录的操作
+type JournalOp string
+
+const (
+	JournalMkdir  JournalOp = "mkdir"  // 创建目录
+	JournalCreate JournalOp = "create" // 创建文件（复制、克隆、硬链接、符号链接）
+	JournalMove   JournalOp = "move"   // 移动文件
+)
+
+// JournalEntry 操作日志中的一项
+// Backup 不为空表示覆盖了已有文件，原文件被移动到该位置
+type JournalEntry struct {
+	Time   time.Time `json:"time"`
+	Op     JournalOp `json:"op"`
+	Path   string    `json:"path"`
+	Source string    `json:"source,omitempty"`
+	Backup string    `json:"backup,omitempty"`
+	Digest Digest    `json:"digest,omitempty"`
+	Link   string    `json:"link,omitempty"` // 符号链接指向的路径
+
+	LegacyMD5 string `json:"md5,omitempty"` // 旧版本日志记录的 MD5，读取时转换为 Digest
+}
+
+// Journal 机器可读的操作日志（JSON Lines），用于撤销一次运行
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+	path string
+	id   string
+	err  error
+}
+
+// NewJournal 在当前目录创建操作日志
+// 标识由时间和随机后缀组成，同一秒内的多次运行不会共用日志或备份目录；
+// 以独占方式创建，已存在的日志不会被截断
+func NewJournal() (*Journal, error) {
+	for {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, err
+		}
+		id := fmt.Sprintf("%s_%x", time.Now().Format("20060102_150405"), suffix)
+		path := fmt.Sprintf("organize_journal_%s.jsonl", id)
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Journal{file: file, path: path, id: id}, nil
+	}
+}
+
+// GetPath 获取操作日志路径
+func (j *Journal) GetPath() string {
+	absPath, _ := filepath.Abs(j.path)
+	return absPath
+}
+
+// record 追加一项操作，写入失败时记住第一个错误并在 Close 时返回
+func (j *Journal) record(entry JournalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err != nil {
+		return
+	}
+	entry.Time = time.Now()
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = j.file.Write(append(data, '\n'))
+	}
+	j.err = err
+}
+
+// Close 关闭操作日志
+func (j *Journal) Close() error {
+	if err := j.file.Close(); j.err == nil {
+		j.err = err
+	}
+	return j.err
+}
+
+// ReadJournal 读取操作日志
+func ReadJournal(path string) ([]JournalEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("无法解析操作日志第 %d 行: %w", line, err)
+		}
+		if entry.Digest == "" && entry.LegacyMD5 != "" {
+			entry.Digest, entry.LegacyMD5 = Digest(entry.LegacyMD5), ""
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// SetJournal 设置操作日志，之后的每个文件系统操作都会被记录
+func (p *Processor) SetJournal(j *Journal) {
+	p.journal = j
+}
+
+// ensureDir 创建目录，并在操作日志中记录新建的每一级目录
+func (p *Processor) ensureDir(dir string) error {
+	// 并发传输时避免同一目录被重复记录
+	p.dirMu.Lock()
+	defer p.dirMu.Unlock()
+
+	// 找出需要新建的目录（由外向内）
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append([]string{d}, missing...)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if p.journal != nil {
+		for _, d := range missing {
+			p.journal.record(JournalEntry{Op: JournalMkdir, Path: d})
+		}
+	}
+	return nil
+}
+
+// backupTarget 记录日志时，覆盖前先将已有目标移动到备份目录，返回备份路径
+func (p *Processor) backupTarget(file *FileInfo) (string, error) {
+	if p.journal == nil {
+		return "", nil
+	}
+	if _, err := os.Lstat(file.TargetPath); err != nil {
+		return "", nil
+	}
+
+	return p.moveToBackup(file.TargetPath, file.Type)
+}
+
+// moveToBackup 将目标库中的文件移动到本次运行的备份目录，返回备份路径
+// 备份目录位于目标根目录下；文件不在配置的目标根目录下时（例如按计划执行），
+// 位于文件所在的目录下，保证与文件在同一设备且不同目录的同名文件不会冲突
+func (p *Processor) moveToBackup(path string, fileType FileType) (string, error) {
+	root := p.config.TargetDirFor(string(fileType))
+	rel, err := filepath.Rel(root, path)
+	if root == "" || err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		root, rel = filepath.Dir(path), filepath.Base(path)
+	}
+	backup := filepath.Join(root, backupDirName, p.journal.id, rel)
+
+	if err := p.ensureDir(filepath.Dir(backup)); err != nil {
+		return "", &TargetWriteError{Path: backup, Err: err}
+	}
+	if err := os.Rename(path, backup); err != nil {
+		return "", &TargetWriteError{Path: path, Err: err}
+	}
+	return backup, nil
+}
+
+// retireReplaced 将被替换的较低分辨率照片移动到备份目录，日志中记录为移动，撤销时放回原位置
+// 只在新照片传输成功且记录日志时调用；计划替换的照片传输失败（不存在）时无需处理
+func (p *Processor) retireReplaced(path string) error {
+	if _, err := os.Lstat(path); err != nil {
+		return nil
+	}
+
+	digest, err := CalculateDigest(context.Background(), path, p.Hasher())
+	if err != nil {
+		return err
+	}
+	backup, err := p.moveToBackup(path, FileTypePhoto)
+	if err != nil {
+		return err
+	}
+	p.journal.record(JournalEntry{Op: JournalMove, Path: backup, Source: path, Digest: digest})
+	return nil
+}
+
+// journalTransfer 记录一次成功的传输
+func (p *Processor) journalTransfer(file *FileInfo, mode config.TransferMode, backup string) {
+	if p.journal == nil {
+		return
+	}
+
+	entry := JournalEntry{Op: JournalCreate, Path: file.TargetPath, Backup: backup}
+	if mode == config.TransferMove {
+		entry.Op = JournalMove
+		entry.Source = file.Path
+	}
+	if mode == config.TransferSymlink {
+		entry.Link, _ = os.Readlink(file.TargetPath)
+	} else {
+		// 传输已经完成，日志必须完整，不响应取消
+		if file.Digest == "" {
+			file.Digest, _ = CalculateDigest(context.Background(), file.TargetPath, p.Hasher())
+		}
+		entry.Digest = file.Digest
+	}
+	p.journal.record(entry)
+}
+
+// UndoRecord 撤销单项操作的结果
+type UndoRecord struct {
+	Entry   JournalEntry
+	Result  ProcessResult
+	Message string
+}
+
+// Undo 按相反顺序撤销操作日志中的操作
+// 内容已被修改的目标文件会被跳过，不会被删除
+func Undo(entries []JournalEntry) []UndoRecord {
+	records := make([]UndoRecord, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		result, message := undoEntry(entry)
+		records = append(records, UndoRecord{Entry: entry, Result: result, Message: message})
+	}
+	return records
+}
+
+// undoEntry 撤销单项操作
+func undoEntry(entry JournalEntry) (ProcessResult, string) {
+	if entry.Op == JournalMkdir {
+		if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
+			return ResultSkipped, i18n.T("undo.missing")
+		}
+		// 只删除空目录
+		if err := os.Remove(entry.Path); err != nil {
+			return ResultSkipped, i18n.T("undo.dir_not_empty")
+		}
+		return ResultSuccess, i18n.T("undo.removed")
+	}
+
+	if entry.Op != JournalCreate && entry.Op != JournalMove {
+		return ResultFailed, i18n.Tf("undo.unknown_op", string(entry.Op))
+	}
+
+	// 确认目标仍是本次运行写入的内容
+	if err := checkUnchanged(entry); err != nil {
+		return ResultSkipped, err.Error()
+	}
+
+	var message string
+	if entry.Op == JournalMove {
+		if _, err := os.Lstat(entry.Source); err == nil {
+			return ResultSkipped, i18n.T("undo.source_exists")
+		}
+		if err := os.MkdirAll(filepath.Dir(entry.Source), 0755); err != nil {
+			return ResultFailed, err.Error()
+		}
+		if err := moveBack(entry); err != nil {
+			return ResultFailed, err.Error()
+		}
+		message = i18n.T("undo.moved_back")
+	} else {
+		if err := os.Remove(entry.Path); err != nil {
+			return ResultFailed, err.Error()
+		}
+		message = i18n.T("undo.removed")
+	}
+
+	// 恢复被覆盖的文件
+	if entry.Backup != "" {
+		if err := os.Rename(entry.Backup, entry.Path); err != nil {
+			return ResultFailed, i18n.Tf("undo.restore_failed", err.Error())
+		}
+		message = i18n.T("undo.restored")
+	}
+	return ResultSuccess, message
+}
+
+// checkUnchanged 检查目标文件是否与日志记录一致
+func checkUnchanged(entry JournalEntry) error {
+	if _, err := os.Lstat(entry.Path); err != nil {
+		return fmt.Errorf("%s", i18n.T("undo.missing"))
+	}
+	if entry.Link != "" {
+		if link, err := os.Readlink(entry.Path); err != nil || link != entry.Link {
+			return fmt.Errorf("%s", i18n.T("undo.changed"))
+		}
+		return nil
+	}
+	hasher, err := entry.Digest.hasher()
+	if err != nil {
+		return err
+	}
+	digest, err := CalculateDigest(context.Background(), entry.Path, hasher)
+	if err != nil {
+		return err
+	}
+	if digest != entry.Digest {
+		return fmt.Errorf("%s", i18n.T("undo.changed"))
+	}
+	return nil
+}
+
+// moveBack 将移动过的文件放回原位置，跨设备时复制并校验后删除
+func moveBack(entry JournalEntry) error {
+	if err := os.Rename(entry.Path, entry.Source); err == nil {
+		return nil
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.PreserveTimes = true
+	cfg.PreserveMode = true
+	// 复制时使用日志记录的摘要算法
+	cfg.HashAlgorithm = entry.Digest.Algorithm()
+	file := &FileInfo{Path: entry.Path, TargetPath: entry.Source}
+	if err := NewProcessor(cfg).copyFile(context.Background(), file); err != nil {
+		return err
+	}
+	if file.Digest != entry.Digest {
+		os.Remove(entry.Source)
+		return &VerifyError{Path: entry.Source, Want: entry.Digest, Got: file.Digest}
+	}
+	return os.Remove(entry.Path)
+}
@@ -0,0 +1,176 @@
+package organizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestJournalUndo(t *testing.T) {
+	tests := []struct {
+		mode config.TransferMode
+	}{
+		{config.TransferCopy},
+		{config.TransferMove},
+		{config.TransferSymlink},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			source := filepath.Join(dir, "source")
+			target := filepath.Join(dir, "target")
+			os.MkdirAll(source, 0755)
+			for name, content := range map[string]string{
+				"IMG_20210304_101530.jpg": "new",
+				"IMG_20210305_101530.jpg": "second",
+				"IMG_20210306_101530.jpg": "third",
+			} {
+				os.WriteFile(filepath.Join(source, name), []byte(content), 0644)
+			}
+			// 已存在的目标文件将被覆盖
+			existing := filepath.Join(target, "2021", "03", "03-04", "IMG_20210304_101530.jpg")
+			os.MkdirAll(filepath.Dir(existing), 0755)
+			os.WriteFile(existing, []byte("old"), 0644)
+
+			cfg := config.NewDefaultConfig()
+			cfg.SourceDir = source
+			cfg.TargetDir = target
+			cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+			cfg.DuplicateStrategy = config.StrategyOverwrite
+			cfg.TransferMode = tt.mode
+
+			journal, err := NewJournal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			processor := NewProcessor(cfg)
+			processor.SetJournal(journal)
+			files, _ := NewScanner(source).Scan(context.Background())
+			for _, file := range files {
+				if record, err := processor.Process(context.Background(), file); err != nil {
+					t.Fatalf("Process(%s) = %v, %v", file.Name, record.Message, err)
+				}
+			}
+			if err := journal.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// 运行后修改其中一个目标，撤销时应保留
+			changed := filepath.Join(target, "2021", "03", "03-06", "IMG_20210306_101530.jpg")
+			if tt.mode == config.TransferSymlink {
+				os.Remove(changed)
+				os.Symlink(filepath.Join(dir, "elsewhere.jpg"), changed)
+			} else {
+				os.WriteFile(changed, []byte("edited"), 0644)
+			}
+
+			entries, err := ReadJournal(journal.GetPath())
+			if err != nil {
+				t.Fatalf("ReadJournal() error = %v", err)
+			}
+			Undo(entries)
+
+			if data, _ := os.ReadFile(existing); string(data) != "old" {
+				t.Errorf("overwritten file content = %q, want old", data)
+			}
+			if _, err := os.Lstat(changed); err != nil {
+				t.Errorf("changed target was removed: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(target, "2021", "03", "03-05")); !os.IsNotExist(err) {
+				t.Errorf("created directory was not removed")
+			}
+			if _, err := os.Stat(filepath.Join(target, backupDirName)); !os.IsNotExist(err) {
+				t.Errorf("backup directory was not removed")
+			}
+			if tt.mode == config.TransferMove {
+				if data, _ := os.ReadFile(filepath.Join(source, "IMG_20210305_101530.jpg")); string(data) != "second" {
+					t.Errorf("moved file was not restored, content = %q", data)
+				}
+			}
+		})
+	}
+}
+
+func TestMoveToBackup(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(t.TempDir())
+	target := filepath.Join(dir, "target")
+
+	journal, err := NewJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	tests := []struct {
+		name      string
+		targetDir string
+		path      string
+		backup    string
+	}{
+		{"Under target root", target, filepath.Join(target, "2021", "x.jpg"),
+			filepath.Join(target, backupDirName, journal.id, "2021", "x.jpg")},
+		// 按计划执行时没有目标根目录，备份到文件所在目录，同名文件不会冲突
+		{"No target root", "", filepath.Join(dir, "a", "x.jpg"),
+			filepath.Join(dir, "a", backupDirName, journal.id, "x.jpg")},
+		{"No target root, same name", "", filepath.Join(dir, "b", "x.jpg"),
+			filepath.Join(dir, "b", backupDirName, journal.id, "x.jpg")},
+		{"Outside target root", target, filepath.Join(dir, "c", "x.jpg"),
+			filepath.Join(dir, "c", backupDirName, journal.id, "x.jpg")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.MkdirAll(filepath.Dir(tt.path), 0755)
+			os.WriteFile(tt.path, []byte(tt.name), 0644)
+
+			cfg := config.NewDefaultConfig()
+			cfg.TargetDir = tt.targetDir
+			processor := NewProcessor(cfg)
+			processor.SetJournal(journal)
+			backup, err := processor.moveToBackup(tt.path, FileTypePhoto)
+			if err != nil {
+				t.Fatalf("moveToBackup() error = %v", err)
+			}
+			if backup != tt.backup {
+				t.Errorf("moveToBackup() = %s, want %s", backup, tt.backup)
+			}
+			if data, _ := os.ReadFile(backup); string(data) != tt.name {
+				t.Errorf("backup content = %q, want %q", data, tt.name)
+			}
+		})
+	}
+}
+
+func TestNewJournalUnique(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// 同一秒内创建的日志不能互相截断或共用备份目录
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		journal, err := NewJournal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		journal.record(JournalEntry{Op: JournalMkdir, Path: "dir"})
+		if err := journal.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if seen[journal.id] || seen[journal.path] {
+			t.Fatalf("journal %s reused", journal.path)
+		}
+		seen[journal.id], seen[journal.path] = true, true
+	}
+	for path := range seen {
+		if filepath.Ext(path) != ".jsonl" {
+			continue
+		}
+		if entries, err := ReadJournal(path); err != nil || len(entries) != 1 {
+			t.Errorf("ReadJournal(%s) = %d entries, %v; want 1", path, len(entries), err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package organizer
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// libraryEntry 目标库中的一个文件
+type libraryEntry struct {
+	path    string    // 在库中的位置
+	source  string    // 本次运行计划写入、可能尚未写入的文件的源路径
+	content *FileInfo // 读取内容用的文件，缓存需要比较时才计算的哈希
+}
+
+// LibraryIndex 整个目标库的内容索引
+// 首次查询时遍历目标目录并按大小分组，只有大小相同的候选才会分级比较内容；
+// 本次运行计划写入的文件也会加入索引。只在分配阶段按顺序调用，不需要加锁
+type LibraryIndex struct {
+	roots  []string
+	hasher Hasher
+	built  bool
+	bySize map[int64][]*libraryEntry
+}
+
+// NewLibraryIndex 创建目标库索引，roots 为照片和视频的目标根目录
+func NewLibraryIndex(hasher Hasher, roots ...string) *LibraryIndex {
+	return &LibraryIndex{roots: roots, hasher: hasher, bySize: make(map[int64][]*libraryEntry)}
+}
+
+// build 遍历目标目录并按大小分组
+func (l *LibraryIndex) build() error {
+	err := walkLibrary(l.roots, func(path string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		l.bySize[info.Size()] = append(l.bySize[info.Size()], &libraryEntry{path: path, content: &FileInfo{Path: path}})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	l.built = true
+	return nil
+}
+
+// walkLibrary 遍历目标库中的媒体文件，跳过备份目录和临时文件
+// 照片和视频的目标根目录相同时只遍历一次，目标目录尚不存在时库为空
+func walkLibrary(roots []string, fn func(path string, d fs.DirEntry) error) error {
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		if root == "" || seen[root] {
+			continue
+		}
+		seen[root] = true
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == backupDirName {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || isTempFile(d.Name()) || getFileType(path) == FileTypeOther {
+				return nil
+			}
+			return fn(path, d)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find 查找库中与文件内容相同的文件，返回其位置；不存在时返回空字符串
+func (l *LibraryIndex) Find(ctx context.Context, file *FileInfo, tiers *HashTiers) (string, error) {
+	if !l.built {
+		if err := l.build(); err != nil {
+			return "", err
+		}
+	}
+
+	info, err := os.Stat(file.Path)
+	if err != nil {
+		return "", err
+	}
+	candidates := l.bySize[info.Size()]
+	if len(candidates) == 0 {
+		tiers.Size++
+		return "", nil
+	}
+
+	// 无法读取的候选文件（例如已被删除或没有权限）不影响与其余文件比较
+	for _, entry := range candidates {
+		same, err := sameContent(ctx, file, entry.file(), info.Size(), l.hasher, tiers)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if err != nil {
+			continue
+		}
+		if same {
+			return entry.path, nil
+		}
+	}
+	return "", nil
+}
+
+// Add 将计划写入 file.TargetPath 的文件加入索引
+func (l *LibraryIndex) Add(file *FileInfo) {
+	info, err := os.Stat(file.Path)
+	if err != nil {
+		return
+	}
+	// 复制已计算的哈希，传输阶段会并发修改 file
+	l.bySize[info.Size()] = append(l.bySize[info.Size()], &libraryEntry{
+		path:    file.TargetPath,
+		source:  file.Path,
+		content: &FileInfo{Path: file.Path, Digest: file.Digest, partial: file.partial},
+	})
+}
+
+// file 返回读取内容用的文件
+// 计划写入的文件在目标写入完成前读取源文件（移动完成后源文件已不存在，读取目标）
+func (e *libraryEntry) file() *FileInfo {
+	if e.source != "" {
+		e.content.Path = e.source
+		if _, err := os.Lstat(e.path); err == nil {
+			e.content.Path = e.path
+		}
+	}
+	return e.content
+}
@@ -0,0 +1,103 @@
+package organizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestLibraryDuplicateDetection(t *testing.T) {
+	// 副本位于其他日期路径时，重命名和覆盖策略也不会再导入一份
+	for _, strategy := range []config.DuplicateStrategy{config.StrategySkip, config.StrategyRename, config.StrategyOverwrite} {
+		t.Run(string(strategy), func(t *testing.T) {
+			testLibraryDuplicateDetection(t, strategy)
+		})
+	}
+}
+
+func testLibraryDuplicateDetection(t *testing.T, strategy config.DuplicateStrategy) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	target := filepath.Join(dir, "target")
+
+	write := func(path, content string) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	// 库中已有的文件，名称和日期都不同
+	existing := filepath.Join(target, "2019", "01", "01-01", "IMG_20190101_000000.jpg")
+	write(existing, "resaved photo")
+	// 备份目录中的文件不属于库
+	write(filepath.Join(target, backupDirName, "20200101_000000", "old.jpg"), "backup only")
+
+	write(filepath.Join(source, "IMG-20210304-WA0001.jpg"), "resaved photo")
+	write(filepath.Join(source, "IMG_20210305_101530.jpg"), "backup only")
+	write(filepath.Join(source, "a", "IMG_20210306_101530.jpg"), "new photo")
+	write(filepath.Join(source, "b", "IMG_20210307_101530.jpg"), "new photo")
+
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = target
+	cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+	cfg.DuplicateDetection = config.DetectionLibrary
+	cfg.DuplicateStrategy = strategy
+
+	processor := NewProcessor(cfg)
+	files, _ := NewScanner(source).Scan(context.Background())
+	records := make(map[string]*ProcessRecord)
+	for _, file := range files {
+		record, err := processor.Process(context.Background(), file)
+		if err != nil {
+			t.Fatalf("Process(%s) error = %v", file.Name, err)
+		}
+		records[file.Name] = record
+	}
+
+	tests := []struct {
+		name        string
+		result      ProcessResult
+		duplicateOf string
+	}{
+		{"IMG-20210304-WA0001.jpg", ResultSkipped, existing},
+		{"IMG_20210305_101530.jpg", ResultSuccess, ""},
+		{"IMG_20210306_101530.jpg", ResultSuccess, ""},
+		// 本次运行中已导入的文件也属于库
+		{"IMG_20210307_101530.jpg", ResultSkipped, records["IMG_20210306_101530.jpg"].File.TargetPath},
+	}
+	for _, tt := range tests {
+		record := records[tt.name]
+		if record.Result != tt.result || record.DuplicateOf != tt.duplicateOf {
+			t.Errorf("%s: result = %s, duplicateOf = %q, want %s, %q",
+				tt.name, record.Result, record.DuplicateOf, tt.result, tt.duplicateOf)
+		}
+	}
+}
+
+func TestLibraryFindUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	// 两个大小相同的候选，第一个在建立索引后被删除
+	removed := filepath.Join(dir, "target", "2018", "IMG_20180101_000000.jpg")
+	existing := filepath.Join(dir, "target", "2019", "IMG_20190101_000000.jpg")
+	write(removed, "other photo!!")
+	write(existing, "resaved photo")
+	source := filepath.Join(dir, "source", "IMG-20210304-WA0001.jpg")
+	write(source, "resaved photo")
+
+	hasher, _ := HasherFor("md5")
+	index := NewLibraryIndex(hasher, filepath.Join(dir, "target"))
+	if err := index.build(); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(removed)
+
+	got, err := index.Find(context.Background(), &FileInfo{Path: source}, &HashTiers{})
+	if err != nil || got != existing {
+		t.Errorf("Find() = %q, %v, want %q", got, err, existing)
+	}
+}
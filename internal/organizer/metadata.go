@@ -1,71 +1,231 @@
 package organizer
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
 	"github.com/rwcarlsen/goexif/exif"
 )
 
+// ErrNoDate 所有日期来源均不可用
+var ErrNoDate = errors.New("没有可用的日期来源")
+
+// exifTimeLayout EXIF 日期格式
+const exifTimeLayout = "2006:01:02 15:04:05"
+
 // MetadataExtractor 元数据提取器
-type MetadataExtractor struct{}
+type MetadataExtractor struct {
+	filenameParser *filenameDateParser
+	chains         map[FileType][]string // 各文件类型的日期回退链
+}
 
 // NewMetadataExtractor 创建元数据提取器
-func NewMetadataExtractor() *MetadataExtractor {
-	return &MetadataExtractor{}
+func NewMetadataExtractor(cfg *config.Config) *MetadataExtractor {
+	return &MetadataExtractor{
+		filenameParser: newFilenameDateParser(cfg.FilenamePatterns),
+		chains: map[FileType][]string{
+			FileTypePhoto: cfg.DateSourcesFor(string(FileTypePhoto)),
+			FileTypeVideo: cfg.DateSourcesFor(string(FileTypeVideo)),
+		},
+	}
 }
 
-// ExtractDate 提取日期
+// ExtractDate 按配置的回退链提取日期，并将日期来源记录到 file.DateSource
+// 所有来源都不可用时返回 ErrNoDate
 func (e *MetadataExtractor) ExtractDate(file *FileInfo) (time.Time, error) {
-	switch file.Type {
-	case FileTypePhoto:
-		return e.extractPhotoDate(file.Path)
-	case FileTypeVideo:
-		return e.extractVideoDate(file.Path)
-	default:
+	chain, ok := e.chains[file.Type]
+	if !ok {
 		return time.Time{}, fmt.Errorf("不支持的文件类型")
 	}
+
+	state := &dateLookup{path: file.Path}
+	for _, name := range chain {
+		t, source, err := e.evaluate(state, name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if source != "" {
+			file.DateSource = source
+			state.fillCamera(file)
+			return t, nil
+		}
+	}
+
+	return time.Time{}, ErrNoDate
+}
+
+// ExtractCamera 读取相机厂商与型号到 file.CameraMake/CameraModel
+// 照片读取 EXIF Make/Model，视频读取 QuickTime 元数据
+func (e *MetadataExtractor) ExtractCamera(file *FileInfo) {
+	if file.CameraMake != "" || file.CameraModel != "" {
+		return
+	}
+
+	if file.Type == FileTypeVideo {
+		f, err := os.Open(file.Path)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return
+		}
+		file.CameraMake, file.CameraModel = readQuickTimeCamera(f, info.Size())
+		return
+	}
+
+	state := &dateLookup{path: file.Path}
+	state.exif, state.exifErr = e.decodeExif(file.Path)
+	state.decoded = true
+	state.fillCamera(file)
+}
+
+// dateLookup 单个文件的日期查找状态，避免重复解析EXIF
+type dateLookup struct {
+	path    string
+	exif    *exif.Exif
+	exifErr error
+	decoded bool
+}
+
+// fillCamera 已解析EXIF时顺便记录相机信息
+func (s *dateLookup) fillCamera(file *FileInfo) {
+	if !s.decoded || s.exifErr != nil {
+		return
+	}
+	file.CameraMake = exifString(s.exif, exif.Make)
+	file.CameraModel = exifString(s.exif, exif.Model)
 }
 
-// extractPhotoDate 提取照片日期
-func (e *MetadataExtractor) extractPhotoDate(path string) (time.Time, error) {
-	// 尝试读取EXIF
+// evaluate 计算单个日期来源，来源不可用时返回空的 DateSource
+func (e *MetadataExtractor) evaluate(state *dateLookup, name string) (time.Time, DateSource, error) {
+	switch name {
+	case config.DateSourceEXIFOriginal, config.DateSourceEXIFCreate, config.DateSourceEXIFModify:
+		if !state.decoded {
+			state.exif, state.exifErr = e.decodeExif(state.path)
+			state.decoded = true
+		}
+		if state.exifErr != nil {
+			return time.Time{}, "", nil
+		}
+		if t, ok := exifTagTime(state.exif, exifDateFields[name]); ok {
+			return t, DateSourceEXIF, nil
+		}
+
+	case config.DateSourceQuickTime:
+		if t, ok := e.readContainerDate(state.path); ok {
+			return t, DateSourceQuickTime, nil
+		}
+
+	case config.DateSourceXMP:
+		if t, ok := readSidecarDate(state.path); ok {
+			return t, DateSourceSidecar, nil
+		}
+
+	case config.DateSourceFilename:
+		if t, ok := e.filenameParser.Parse(filepath.Base(state.path)); ok {
+			return t, DateSourceFilename, nil
+		}
+
+	case config.DateSourceMtime:
+		t, err := e.getFileCreationTime(state.path)
+		if err != nil {
+			return time.Time{}, "", err
+		}
+		return t, DateSourceMtime, nil
+	}
+
+	return time.Time{}, "", nil
+}
+
+// exifDateFields 日期来源名称对应的 EXIF 字段
+var exifDateFields = map[string]exif.FieldName{
+	config.DateSourceEXIFOriginal: exif.DateTimeOriginal,
+	config.DateSourceEXIFCreate:   exif.DateTimeDigitized,
+	config.DateSourceEXIFModify:   exif.DateTime,
+}
+
+// exifTagTime 读取 EXIF 日期字段
+// 时区取自 OffsetTime* 标签或 GPS 时间，均不可用时按本地时间解析
+func exifTagTime(x *exif.Exif, field exif.FieldName) (time.Time, bool) {
+	tag, err := x.Get(field)
+	if err != nil {
+		return time.Time{}, false
+	}
+	dateStr, err := tag.StringVal()
+	if err != nil {
+		return time.Time{}, false
+	}
+	dateStr = strings.TrimRight(dateStr, "\x00 ")
+
+	t, err := time.ParseInLocation(exifTimeLayout, dateStr, exifLocation(x, field, dateStr))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// exifString 读取 EXIF 字符串字段
+func exifString(x *exif.Exif, field exif.FieldName) string {
+	tag, err := x.Get(field)
+	if err != nil {
+		return ""
+	}
+	value, err := tag.StringVal()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(value, "\x00"))
+}
+
+// decodeExif 按文件格式读取EXIF
+func (e *MetadataExtractor) decodeExif(path string) (*exif.Exif, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return e.getFileCreationTime(path)
+		return nil, err
 	}
 	defer f.Close()
 
-	x, err := exif.Decode(f)
+	info, err := f.Stat()
 	if err != nil {
-		return e.getFileCreationTime(path)
+		return nil, err
 	}
 
-	// 尝试获取拍摄时间
-	dateTime, err := x.DateTime()
-	if err == nil {
-		return dateTime, nil
+	// 需要特殊处理的格式（HEIF、CR3、RAF等）
+	ext := strings.ToLower(filepath.Ext(path))
+	if reader, ok := exifReaders[ext]; ok {
+		return reader(f, info.Size())
 	}
 
-	// 尝试获取原始拍摄时间
-	tag, err := x.Get(exif.DateTimeOriginal)
-	if err == nil {
-		if dateStr, err := tag.StringVal(); err == nil {
-			if t, err := time.Parse("2006:01:02 15:04:05", dateStr); err == nil {
-				return t, nil
-			}
-		}
+	// 扩展名与实际格式不符的 HEIF 文件
+	if isHEIF(f) {
+		return decodeHEIFExif(f, info.Size())
 	}
 
-	// 回退到文件创建时间
-	return e.getFileCreationTime(path)
+	// JPEG 及 TIFF 类 RAW（ARW/DNG/NEF/CR2等）
+	return decodeExifFrom(f)
 }
 
-// extractVideoDate 提取视频日期
-func (e *MetadataExtractor) extractVideoDate(path string) (time.Time, error) {
-	// 视频直接使用文件创建时间
-	return e.getFileCreationTime(path)
+// readContainerDate 读取 MP4/MOV 容器中的创建时间
+func (e *MetadataExtractor) readContainerDate(path string) (time.Time, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return readQuickTimeCreationDate(f, info.Size())
 }
 
 // getFileCreationTime 获取文件创建时间
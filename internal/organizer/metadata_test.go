@@ -0,0 +1,68 @@
+package organizer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestExtractDateChain(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	exifPhoto := write("IMG_20200101_080000.tif", testTIFF("2021:03:04 10:15:30"))
+	sidecarPhoto := write("DSC00012.ARW", []byte("not a tiff"))
+	write("DSC00012.xmp", []byte(`<rdf:Description exif:DateTimeOriginal="2019-07-08T09:10:11"/>`))
+	plainPhoto := write("DSC00013.ARW", []byte("not a tiff"))
+
+	tests := []struct {
+		name         string
+		path         string
+		sources      []string
+		disableMtime bool
+		expected     time.Time
+		source       DateSource
+		err          error
+	}{
+		{"Default EXIF", exifPhoto, nil, false, time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local), DateSourceEXIF, nil},
+		{"Filename first", exifPhoto, []string{"filename", "exif.DateTimeOriginal"}, false, time.Date(2020, 1, 1, 8, 0, 0, 0, time.Local), DateSourceFilename, nil},
+		{"XMP sidecar", sidecarPhoto, nil, false, time.Date(2019, 7, 8, 9, 10, 11, 0, time.Local), DateSourceSidecar, nil},
+		{"Mtime fallback", plainPhoto, nil, false, mtime, DateSourceMtime, nil},
+		{"Mtime refused", plainPhoto, nil, true, time.Time{}, "", ErrNoDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.DisableMtimeFallback = tt.disableMtime
+			if tt.sources != nil {
+				cfg.DateSources = map[string][]string{"photo": tt.sources}
+			}
+
+			file := &FileInfo{Path: tt.path, Name: filepath.Base(tt.path), Type: FileTypePhoto}
+			result, err := NewMetadataExtractor(cfg).ExtractDate(file)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ExtractDate() error = %v, want %v", err, tt.err)
+			}
+			if !result.Equal(tt.expected) || file.DateSource != tt.source {
+				t.Errorf("ExtractDate() = %v (%s), want %v (%s)",
+					result, file.DateSource, tt.expected, tt.source)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+//go:build linux
+
+package organizer
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dropPageCache 尽力将文件写回磁盘并从页缓存中丢弃，之后的读取来自磁盘而不是刚写入的缓存
+// 只能丢弃本进程写入的干净页，磁盘或控制器自身的缓存仍可能命中；失败时忽略
+func dropPageCache(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if f.Sync() == nil {
+		unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package organizer
+
+// dropPageCache 当前平台无法丢弃文件的页缓存，校验读取的可能是刚写入的缓存
+func dropPageCache(path string) {}
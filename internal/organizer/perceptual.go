@@ -0,0 +1,236 @@
+package organizer
+
+import (
+	"context"
+	"image"
+	"image/color"
+	_ "image/jpeg" // 注册 JPEG 解码器
+	_ "image/png"  // 注册 PNG 解码器
+	"io/fs"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// perceptualExts 可以计算感知哈希的格式（标准库能解码的照片）
+var perceptualExts = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+}
+
+// perceptualSamples 缩小图像时每个方向的最少采样数，大图按步长采样而不是逐像素读取
+const perceptualSamples = 256
+
+// perceptualInfo 照片的感知哈希和分辨率
+type perceptualInfo struct {
+	hash   uint64 // 差异哈希（dHash）
+	pixels int    // 像素数（宽×高）
+}
+
+// supportsPerceptual 判断文件格式是否可以计算感知哈希
+func supportsPerceptual(path string) bool {
+	return perceptualExts[strings.ToLower(filepath.Ext(path))]
+}
+
+// perceptualHash 解码照片并计算差异哈希（dHash）
+// 将图像缩小为 9×8 的灰度图，逐行比较相邻像素的亮度得到 64 位哈希；
+// 缩放、重新压缩后的副本只有少数位不同，用汉明距离衡量相似程度
+func perceptualHash(ctx context.Context, path string) (perceptualInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return perceptualInfo{}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return perceptualInfo{}, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return perceptualInfo{}, err
+	}
+
+	const width, height = 9, 8
+	gray := shrinkGray(img, width, height)
+	var hash uint64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width-1; x++ {
+			hash <<= 1
+			if gray[y*width+x] < gray[y*width+x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	bounds := img.Bounds()
+	return perceptualInfo{hash: hash, pixels: bounds.Dx() * bounds.Dy()}, nil
+}
+
+// shrinkGray 将图像按区域平均缩小为 width×height 的灰度值
+func shrinkGray(img image.Image, width, height int) []float64 {
+	bounds := img.Bounds()
+	sums := make([]float64, width*height)
+	counts := make([]int, width*height)
+	step := max(1, min(bounds.Dx(), bounds.Dy())/perceptualSamples)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		cy := (y - bounds.Min.Y) * height / bounds.Dy()
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			cx := (x - bounds.Min.X) * width / bounds.Dx()
+			sums[cy*width+cx] += float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+			counts[cy*width+cx]++
+		}
+	}
+	for i := range sums {
+		if counts[i] > 0 {
+			sums[i] /= float64(counts[i])
+		}
+	}
+	return sums
+}
+
+// hammingDistance 两个感知哈希不同的位数
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// similarEntry 目标库中的一张照片
+type similarEntry struct {
+	path     string
+	info     perceptualInfo
+	replaced bool // 已被分辨率更高的相似照片替换，不再参与比较
+}
+
+// SimilarMatch 目标库中与文件相似的照片
+type SimilarMatch struct {
+	Path     string // 在库中的位置
+	Distance int    // 感知哈希的汉明距离
+	Pixels   int    // 像素数
+
+	entry *similarEntry
+}
+
+// SimilarIndex 目标库照片的感知哈希索引
+// 首次查询时才建立索引（没有可比较的照片时不会读取目标库），本次运行计划写入的照片也会加入索引。
+// 解码照片的开销较大（大型目标库首次可能需要数分钟），因此结果按路径缓存在用户缓存目录中，
+// 之后的运行只重新解码大小或修改时间变化了的照片，其余照片只需遍历目录。
+// 只在分配阶段按顺序调用，不需要加锁
+type SimilarIndex struct {
+	roots     []string
+	threshold int
+	built     bool
+	entries   []*similarEntry
+}
+
+// NewSimilarIndex 创建感知哈希索引，汉明距离不超过 threshold 的照片视为相似
+func NewSimilarIndex(threshold int, roots ...string) *SimilarIndex {
+	return &SimilarIndex{roots: roots, threshold: threshold}
+}
+
+// build 遍历目标目录并计算照片的感知哈希，无法解码的照片不参与比较
+// 缓存中大小和修改时间都未变化的照片直接使用缓存，其余照片并发解码，完成后更新缓存
+func (s *SimilarIndex) build(ctx context.Context) error {
+	cachePath := perceptualCachePath(s.roots)
+	cached := loadPerceptualCache(cachePath)
+
+	var paths []string
+	entries := make(map[string]perceptualCacheEntry)
+	var decode []int // 需要解码的照片（paths 中的位置）
+	err := walkLibrary(s.roots, func(path string, d fs.DirEntry) error {
+		if !supportsPerceptual(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entry := perceptualCacheEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		if old, ok := cached[path]; ok && old.Size == entry.Size && old.ModTime == entry.ModTime {
+			entry = old
+		} else {
+			decode = append(decode, len(paths))
+		}
+		paths = append(paths, path)
+		entries[path] = entry
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	infos := make([]*perceptualInfo, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if info, err := perceptualHash(ctx, paths[j]); err == nil {
+					infos[j] = &info
+				}
+			}
+		}()
+	}
+	for _, j := range decode {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, j := range decode {
+		entry := entries[paths[j]]
+		if info := infos[j]; info != nil {
+			entry.Hash, entry.Pixels = info.hash, info.pixels
+		} else {
+			entry.Failed = true
+		}
+		entries[paths[j]] = entry
+	}
+	for _, path := range paths {
+		if entry := entries[path]; !entry.Failed {
+			s.entries = append(s.entries, &similarEntry{path: path, info: perceptualInfo{hash: entry.Hash, pixels: entry.Pixels}})
+		}
+	}
+	// 缓存只是加速，保存失败不影响本次运行
+	if len(decode) > 0 || len(entries) != len(cached) {
+		savePerceptualCache(cachePath, entries)
+	}
+	s.built = true
+	return nil
+}
+
+// Find 查找库中与照片最相似的一张，没有汉明距离在阈值内的照片时返回 nil
+func (s *SimilarIndex) Find(ctx context.Context, info perceptualInfo) (*SimilarMatch, error) {
+	if !s.built {
+		if err := s.build(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var best *SimilarMatch
+	for _, entry := range s.entries {
+		if entry.replaced {
+			continue
+		}
+		distance := hammingDistance(info.hash, entry.info.hash)
+		if distance > s.threshold || (best != nil && distance >= best.Distance) {
+			continue
+		}
+		best = &SimilarMatch{Path: entry.path, Distance: distance, Pixels: entry.info.pixels, entry: entry}
+	}
+	return best, nil
+}
+
+// Add 将计划写入 path 的照片加入索引
+func (s *SimilarIndex) Add(path string, info perceptualInfo) {
+	s.entries = append(s.entries, &similarEntry{path: path, info: info})
+}
@@ -0,0 +1,86 @@
+package organizer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// perceptualCacheVersion 感知哈希缓存格式版本
+const perceptualCacheVersion = 1
+
+// perceptualCacheEntry 缓存的单张照片，大小或修改时间变化后重新计算
+type perceptualCacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"` // 修改时间（Unix 纳秒）
+	Hash    uint64 `json:"hash,omitempty"`
+	Pixels  int    `json:"pixels,omitempty"`
+	Failed  bool   `json:"failed,omitempty"` // 无法解码，不参与比较
+}
+
+// perceptualCache 目标库照片的感知哈希缓存（以路径为键）
+type perceptualCache struct {
+	Version int                             `json:"version"`
+	Entries map[string]perceptualCacheEntry `json:"entries"`
+}
+
+// perceptualCachePath 返回目标目录对应的感知哈希缓存路径
+// 与检查点一样保存在用户缓存目录中，以目标目录的绝对路径为键
+func perceptualCachePath(roots []string) string {
+	hash := sha256.New()
+	for _, root := range roots {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		fmt.Fprintf(hash, "%s\x00", root)
+	}
+	name := fmt.Sprintf("%x.json", hash.Sum(nil))
+	return filepath.Join(cacheDir(), "perceptual", name)
+}
+
+// cacheDir 返回本程序的缓存目录，无法确定用户缓存目录时使用临时目录
+func cacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "media-organizer")
+}
+
+// loadPerceptualCache 读取缓存，缓存不存在或无法读取时返回空缓存
+func loadPerceptualCache(path string) map[string]perceptualCacheEntry {
+	var cache perceptualCache
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &cache) != nil || cache.Version != perceptualCacheVersion || cache.Entries == nil {
+		return make(map[string]perceptualCacheEntry)
+	}
+	return cache.Entries
+}
+
+// savePerceptualCache 保存缓存，先写入临时文件再重命名，中断时不会留下不完整的缓存
+func savePerceptualCache(path string, entries map[string]perceptualCacheEntry) error {
+	data, err := json.Marshal(perceptualCache{Version: perceptualCacheVersion, Entries: entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+"*"+tempFileSuffix)
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,257 @@
+package organizer
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+// writePhoto 生成 width×height 的测试照片，scene 不同时内容不同，按扩展名编码为 JPEG 或 PNG
+func writePhoto(t *testing.T, path string, width, height int, scene float64) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			u, v := float64(x)/float64(width), float64(y)/float64(height)
+			level := 128 + 120*math.Sin(scene*u*7+v*3)*math.Cos(v*5-scene*u)
+			img.Set(x, y, color.Gray{Y: uint8(level)})
+		}
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0755)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if strings.HasSuffix(path, ".png") {
+		err = png.Encode(file, img)
+	} else {
+		err = jpeg.Encode(file, img, &jpeg.Options{Quality: 60})
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPerceptualHash(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "original.png")
+	writePhoto(t, original, 640, 480, 1)
+	want, err := perceptualHash(context.Background(), original)
+	if err != nil {
+		t.Fatalf("perceptualHash() error = %v", err)
+	}
+	if want.pixels != 640*480 {
+		t.Errorf("pixels = %d, want %d", want.pixels, 640*480)
+	}
+
+	tests := []struct {
+		name    string
+		width   int
+		height  int
+		scene   float64
+		similar bool
+	}{
+		{"resized.jpg", 160, 120, 1, true},
+		{"recompressed.jpg", 640, 480, 1, true},
+		{"other.png", 640, 480, 2.5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			writePhoto(t, path, tt.width, tt.height, tt.scene)
+			got, err := perceptualHash(context.Background(), path)
+			if err != nil {
+				t.Fatalf("perceptualHash() error = %v", err)
+			}
+			distance := hammingDistance(want.hash, got.hash)
+			if similar := distance <= config.DefaultPerceptualThreshold; similar != tt.similar {
+				t.Errorf("distance = %d, similar = %v, want %v", distance, similar, tt.similar)
+			}
+		})
+	}
+}
+
+func TestKeepHigherResolution(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	source := filepath.Join(dir, "source")
+	target := filepath.Join(dir, "target")
+
+	// 库中已有一张较小的副本（例如聊天软件转发的图片）
+	existing := filepath.Join(target, "2019", "01", "01-01", "IMG-20190101-WA0001.jpg")
+	writePhoto(t, existing, 320, 240, 1)
+	writePhoto(t, filepath.Join(source, "IMG_20210304_101530.png"), 1280, 960, 1)
+	writePhoto(t, filepath.Join(source, "IMG_20210305_101530.jpg"), 160, 120, 1)
+	writePhoto(t, filepath.Join(source, "IMG_20210306_101530.png"), 640, 480, 2.5)
+
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = target
+	cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+	cfg.DuplicateDetection = config.DetectionPerceptual
+	cfg.KeepHigherResolution = true
+
+	journal, err := NewJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor := NewProcessor(cfg)
+	processor.SetJournal(journal)
+	files, _ := NewScanner(source).Scan(context.Background())
+	records := make(map[string]*ProcessRecord)
+	for _, file := range files {
+		record, err := processor.Process(context.Background(), file)
+		if err != nil {
+			t.Fatalf("Process(%s) error = %v", file.Name, err)
+		}
+		records[file.Name] = record
+	}
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	imported := records["IMG_20210304_101530.png"].File.TargetPath
+	tests := []struct {
+		name        string
+		result      ProcessResult
+		duplicateOf string
+	}{
+		// 分辨率更高，替换库中的副本
+		{"IMG_20210304_101530.png", ResultSuccess, existing},
+		// 与刚导入的照片相比分辨率较低
+		{"IMG_20210305_101530.jpg", ResultSkipped, imported},
+		{"IMG_20210306_101530.png", ResultSuccess, ""},
+	}
+	for _, tt := range tests {
+		record := records[tt.name]
+		if record.Result != tt.result || record.DuplicateOf != tt.duplicateOf {
+			t.Errorf("%s: result = %s, duplicateOf = %q, want %s, %q",
+				tt.name, record.Result, record.DuplicateOf, tt.result, tt.duplicateOf)
+		}
+	}
+	if _, err := os.Stat(existing); !os.IsNotExist(err) {
+		t.Errorf("replaced photo still in the library: %v", err)
+	}
+
+	// 撤销后被替换的照片回到原位置
+	entries, err := ReadJournal(journal.GetPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range Undo(entries) {
+		if record.Result == ResultFailed {
+			t.Errorf("undo %s: %s", record.Entry.Path, record.Message)
+		}
+	}
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("replaced photo not restored: %v", err)
+	}
+	if _, err := os.Stat(imported); !os.IsNotExist(err) {
+		t.Errorf("imported photo not removed: %v", err)
+	}
+}
+
+func TestKeepHigherResolutionNotReplaced(t *testing.T) {
+	tests := []struct {
+		name    string
+		journal bool
+		block   bool // 目标目录被同名文件占用，传输失败
+		result  ProcessResult
+	}{
+		{"Without journal", false, false, ResultSuccess},
+		{"Transfer failed", true, true, ResultFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			dir := t.TempDir()
+			t.Chdir(dir)
+			source := filepath.Join(dir, "source")
+			target := filepath.Join(dir, "target")
+			existing := filepath.Join(target, "2019", "01", "01-01", "IMG-20190101-WA0001.jpg")
+			writePhoto(t, existing, 320, 240, 1)
+			writePhoto(t, filepath.Join(source, "IMG_20210304_101530.png"), 1280, 960, 1)
+			if tt.block {
+				os.WriteFile(filepath.Join(target, "2021"), []byte("not a directory"), 0644)
+			}
+
+			cfg := config.NewDefaultConfig()
+			cfg.TargetDir = target
+			cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+			cfg.DuplicateDetection = config.DetectionPerceptual
+			cfg.KeepHigherResolution = true
+
+			processor := NewProcessor(cfg)
+			if tt.journal {
+				journal, err := NewJournal()
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer journal.Close()
+				processor.SetJournal(journal)
+			}
+			files, _ := NewScanner(source).Scan(context.Background())
+			record, _ := processor.Process(context.Background(), files[0])
+
+			// 库中的照片保留，记录不能声称已替换
+			if record.Result != tt.result || record.File.Replaces != "" {
+				t.Errorf("result = %s, replaces = %q, want %s and nothing replaced", record.Result, record.File.Replaces, tt.result)
+			}
+			if _, err := os.Stat(existing); err != nil {
+				t.Errorf("library photo removed: %v", err)
+			}
+			if tt.result == ResultSuccess && record.File.SimilarTo != existing {
+				t.Errorf("similarTo = %q, want %q", record.File.SimilarTo, existing)
+			}
+		})
+	}
+}
+
+func TestSimilarIndexCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	target := t.TempDir()
+	photo := filepath.Join(target, "2019", "IMG_0001.jpg")
+	writePhoto(t, photo, 320, 240, 1)
+	info, err := perceptualHash(context.Background(), photo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	find := func() *SimilarMatch {
+		match, err := NewSimilarIndex(10, target).Find(context.Background(), info)
+		if err != nil {
+			t.Fatalf("Find() error = %v", err)
+		}
+		return match
+	}
+	if match := find(); match == nil || match.Path != photo {
+		t.Fatalf("Find() = %+v, want %s", match, photo)
+	}
+
+	// 大小和修改时间未变时使用缓存，不再解码
+	stat, _ := os.Stat(photo)
+	os.WriteFile(photo, make([]byte, stat.Size()), 0644)
+	os.Chtimes(photo, stat.ModTime(), stat.ModTime())
+	if match := find(); match == nil || match.Path != photo {
+		t.Errorf("Find() with cache = %+v, want %s", match, photo)
+	}
+
+	// 修改时间变化后重新解码
+	os.Chtimes(photo, stat.ModTime().Add(time.Second), stat.ModTime().Add(time.Second))
+	if match := find(); match != nil {
+		t.Errorf("Find() after change = %+v, want nil", match)
+	}
+}
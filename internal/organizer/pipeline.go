@@ -0,0 +1,164 @@
+package organizer
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// PipelineResult 并发处理中单个文件的结果
+type PipelineResult struct {
+	Index  int            // 文件在输入中的位置
+	Record *ProcessRecord // 处理记录，因取消而未处理时为 nil
+	Err    error          // 处理错误
+}
+
+// pipelineJob 单个文件在管线中的状态
+type pipelineJob struct {
+	file     *FileInfo
+	action   PlanAction
+	record   *ProcessRecord
+	err      error
+	prepared chan struct{} // 准备阶段完成
+	done     chan struct{} // 处理完成
+}
+
+// finish 记录结果并标记完成
+func (j *pipelineJob) finish(record *ProcessRecord, err error) {
+	j.record, j.err = record, err
+	close(j.done)
+}
+
+// ProcessAll 使用有界工作池并发处理文件，结果按输入顺序发送到返回的通道
+// 读取元数据和传输文件由 workers 个工作协程并发执行（workers <= 0 时使用 CPU 核数）；
+// 分配目标路径按输入顺序逐个执行，因此序号和 (1) 后缀与顺序处理完全一致，不会被两个文件同时占用
+// 源目录中的重复文件等待所在组的代表处理完成：代表没有导入（例如没有日期或写入失败）时，
+// 由组中下一个文件代替导入，内容不会因此丢失
+// ctx 取消后不再开始新文件，正在传输的文件在数据块边界停止；所有文件仍会返回结果，未处理的记录为 nil
+func (p *Processor) ProcessAll(ctx context.Context, files []*FileInfo, workers int) <-chan PipelineResult {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make([]*pipelineJob, len(files))
+	for i, file := range files {
+		jobs[i] = &pipelineJob{file: file, prepared: make(chan struct{}), done: make(chan struct{})}
+	}
+
+	// 工作池：执行准备和传输任务
+	tasks := make(chan func())
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range tasks {
+				task()
+			}
+		}()
+	}
+
+	// 按顺序提交准备任务
+	var submit sync.WaitGroup
+	submit.Add(1)
+	go func() {
+		defer submit.Done()
+		for _, job := range jobs {
+			job := job
+			// 源目录中的重复文件在分配阶段确定需要导入时才准备
+			if ctx.Err() != nil || job.file.SourceDuplicateOf != "" {
+				job.err = ctx.Err()
+				close(job.prepared)
+				continue
+			}
+			tasks <- func() {
+				job.record, job.err = p.prepare(ctx, job.file)
+				close(job.prepared)
+			}
+		}
+	}()
+
+	// 按顺序分配目标路径，再提交传输任务
+	submit.Add(1)
+	go func() {
+		defer submit.Done()
+		inflight := make(map[string]*pipelineJob) // 目标路径 → 最近一个写入该路径的文件
+		wait := func(target string) {
+			if previous, ok := inflight[target]; ok {
+				<-previous.done
+			}
+		}
+		attempts := make(map[string]*pipelineJob) // 源目录重复组的代表 → 最近一个尝试导入该组内容的文件
+
+		for _, job := range jobs {
+			<-job.prepared
+			if job.file.SourceDuplicateOf == "" {
+				attempts[job.file.Path] = job
+			}
+			if job.record != nil || job.err != nil {
+				job.finish(job.record, job.err)
+				continue
+			}
+			if ctx.Err() != nil {
+				job.finish(nil, ctx.Err())
+				continue
+			}
+
+			if representative := job.file.SourceDuplicateOf; representative != "" {
+				attempt, ok := attempts[representative]
+				if ok {
+					<-attempt.done
+				}
+				if ctx.Err() != nil {
+					job.finish(nil, ctx.Err())
+					continue
+				}
+				// 代表不在本次处理的文件中（续传时已在之前的运行中处理）时视为已导入
+				if !ok || keepsContent(attempt.record) {
+					if ok {
+						job.file.SourceDuplicateOf = attempt.file.Path
+					}
+					job.finish(sourceDuplicateRecord(job.file), nil)
+					continue
+				}
+				// 代表没有导入，由本文件代替
+				job.file.SourceDuplicateOf = ""
+				attempts[representative] = job
+				if record, err := p.prepare(ctx, job.file); record != nil || err != nil {
+					job.finish(record, err)
+					continue
+				}
+			}
+
+			action, record, err := p.assign(ctx, job.file, wait)
+			if record != nil || err != nil {
+				job.finish(record, err)
+				continue
+			}
+			job.action = action
+			inflight[job.file.TargetPath] = job
+
+			job := job
+			tasks <- func() {
+				job.finish(p.execute(ctx, job.file, job.action))
+			}
+		}
+	}()
+
+	go func() {
+		submit.Wait()
+		close(tasks)
+		wg.Wait()
+	}()
+
+	// 按输入顺序输出结果
+	results := make(chan PipelineResult)
+	go func() {
+		defer close(results)
+		for i, job := range jobs {
+			<-job.done
+			results <- PipelineResult{Index: i, Record: job.record, Err: job.err}
+		}
+	}()
+	return results
+}
@@ -0,0 +1,121 @@
+package organizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestProcessAll(t *testing.T) {
+	tests := []struct {
+		name   string
+		rename string
+	}{
+		{"rename suffix", ""},
+		{"sequence", "{date:20060102}_{seq:02}{ext}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// 不同子目录中的同名文件，目标路径相同
+			var files []*FileInfo
+			for i := 0; i < 40; i++ {
+				path := filepath.Join(dir, "source", fmt.Sprintf("%02d", i), "IMG_20210304_101530.jpg")
+				os.MkdirAll(filepath.Dir(path), 0755)
+				os.WriteFile(path, []byte(fmt.Sprintf("content %d", i)), 0644)
+				files = append(files, &FileInfo{Path: path, Name: filepath.Base(path), Type: FileTypePhoto})
+			}
+
+			cfg := config.NewDefaultConfig()
+			cfg.TargetDir = filepath.Join(dir, "target")
+			cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+			cfg.DuplicateDetection = config.DetectionFilename
+			cfg.DuplicateStrategy = config.StrategyRename
+			cfg.RenamePattern = tt.rename
+
+			// 顺序处理（演练）得到期望的目标路径
+			planner := NewProcessor(withDryRun(cfg))
+			var want []string
+			for _, file := range files {
+				copied := *file
+				record, _ := planner.Process(context.Background(), &copied)
+				want = append(want, record.File.TargetPath)
+			}
+
+			seen := make(map[string]bool)
+			index := 0
+			for result := range NewProcessor(cfg).ProcessAll(context.Background(), files, 8) {
+				if result.Index != index {
+					t.Fatalf("result %d arrived at position %d", result.Index, index)
+				}
+				if result.Err != nil || result.Record.Result != ResultSuccess {
+					t.Fatalf("file %d: %s, %v", index, result.Record.Message, result.Err)
+				}
+				target := result.Record.File.TargetPath
+				if target != want[index] {
+					t.Errorf("file %d target = %s, want %s", index, target, want[index])
+				}
+				if seen[target] {
+					t.Errorf("target %s claimed twice", target)
+				}
+				seen[target] = true
+				index++
+			}
+			if index != len(files) {
+				t.Fatalf("got %d results, want %d", index, len(files))
+			}
+
+			for i := range files {
+				data, err := os.ReadFile(want[i])
+				if err != nil || string(data) != fmt.Sprintf("content %d", i) {
+					t.Errorf("%s content = %q, %v", want[i], data, err)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessAllCancelled(t *testing.T) {
+	dir := t.TempDir()
+	var files []*FileInfo
+	for i := 0; i < 10; i++ {
+		path := filepath.Join(dir, "source", fmt.Sprintf("IMG_20210304_1015%02d.jpg", i))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("content"), 0644)
+		files = append(files, &FileInfo{Path: path, Name: filepath.Base(path), Type: FileTypePhoto})
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = filepath.Join(dir, "target")
+	cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	count := 0
+	for result := range NewProcessor(cfg).ProcessAll(ctx, files, 4) {
+		if result.Record != nil || !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("file %d: record = %v, err = %v, want cancelled", result.Index, result.Record, result.Err)
+		}
+		count++
+	}
+	if count != len(files) {
+		t.Errorf("got %d results, want %d", count, len(files))
+	}
+	if _, err := os.Stat(cfg.TargetDir); !os.IsNotExist(err) {
+		t.Errorf("target dir was created after cancellation")
+	}
+}
+
+// withDryRun 返回开启演练模式的配置副本
+func withDryRun(cfg *config.Config) *config.Config {
+	copied := *cfg
+	copied.DryRun = true
+	return &copied
+}
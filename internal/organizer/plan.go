@@ -0,0 +1,227 @@
+package organizer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
+)
+
+// planVersion 计划文件格式版本
+// 版本 2 将 md5 字段改为记录算法的 digest，版本 1 的文件读取时转换
+const planVersion = 2
+
+// Plan 可编辑的整理计划（由演练生成，保存为 JSON 后可手动修改再执行）
+type Plan struct {
+	Version   int         `json:"version"`
+	CreatedAt time.Time   `json:"createdAt"`
+	SourceDir string      `json:"sourceDir"`
+	Entries   []PlanEntry `json:"entries"`
+
+	// 生成计划时的目标根目录，执行时未指定目标目录则使用（备份和清理临时文件）
+	PhotoTargetDir string `json:"photoTargetDir,omitempty"`
+	VideoTargetDir string `json:"videoTargetDir,omitempty"`
+}
+
+// PlanEntry 计划中的单个文件
+// Size、ModTime、Digest 记录生成计划时源文件的状态，执行前用于检测变化
+type PlanEntry struct {
+	Source     string              `json:"source"`
+	Target     string              `json:"target"`
+	Action     PlanAction          `json:"action"`
+	Transfer   config.TransferMode `json:"transfer"`
+	Type       FileType            `json:"type"`
+	Date       time.Time           `json:"date"`
+	DateSource DateSource          `json:"dateSource"`
+	Size       int64               `json:"size"`
+	ModTime    time.Time           `json:"modTime"`
+	Digest     Digest              `json:"digest"`
+
+	LegacyMD5 string `json:"md5,omitempty"` // 版本 1 记录的 MD5，读取时转换为 Digest
+}
+
+// NewPlan 由演练记录生成计划，失败的记录不会进入计划
+// 尚未计算摘要的源文件使用 hasher 计算
+func NewPlan(ctx context.Context, sourceDir string, records []ProcessRecord, hasher Hasher) (*Plan, error) {
+	plan := &Plan{
+		Version:   planVersion,
+		CreatedAt: time.Now(),
+		SourceDir: sourceDir,
+	}
+
+	for _, record := range records {
+		if record.Result == ResultFailed {
+			continue
+		}
+
+		file := record.File
+		info, err := os.Stat(file.Path)
+		if err != nil {
+			return nil, err
+		}
+		if file.Digest == "" {
+			if file.Digest, err = CalculateDigest(ctx, file.Path, hasher); err != nil {
+				return nil, err
+			}
+		}
+
+		action := record.Action
+		if record.Result == ResultSkipped {
+			action = ActionSkip
+		}
+
+		plan.Entries = append(plan.Entries, PlanEntry{
+			Source:     file.Path,
+			Target:     file.TargetPath,
+			Action:     action,
+			Transfer:   record.Transfer,
+			Type:       file.Type,
+			Date:       file.Date,
+			DateSource: file.DateSource,
+			Size:       info.Size(),
+			ModTime:    info.ModTime(),
+			Digest:     file.Digest,
+		})
+	}
+
+	return plan, nil
+}
+
+// Save 保存计划到 JSON 文件
+func (p *Plan) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadPlan 读取计划文件
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("无法解析计划文件: %w", err)
+	}
+	switch plan.Version {
+	case planVersion:
+	case 1:
+		for i := range plan.Entries {
+			entry := &plan.Entries[i]
+			entry.Digest, entry.LegacyMD5 = Digest(entry.LegacyMD5), ""
+		}
+		plan.Version = planVersion
+	default:
+		return nil, fmt.Errorf("不支持的计划文件版本: %d", plan.Version)
+	}
+	return &plan, nil
+}
+
+// Apply 按计划执行单个条目
+// 源文件的大小、修改时间或哈希与计划记录不一致时视为失败，不会重新计算目标
+// ctx 取消时删除未完成的目标并返回 nil
+func (p *Processor) Apply(ctx context.Context, entry PlanEntry) *ProcessRecord {
+	file := &FileInfo{
+		Path:       entry.Source,
+		Name:       filepath.Base(entry.Source),
+		Type:       entry.Type,
+		Size:       entry.Size,
+		Date:       entry.Date,
+		DateSource: entry.DateSource,
+		TargetPath: entry.Target,
+	}
+	record := &ProcessRecord{File: file, Action: entry.Action, Transfer: entry.Transfer}
+
+	if entry.Action == ActionSkip {
+		record.Result = ResultSkipped
+		record.Message = i18n.T("message.plan_skipped")
+		return record
+	}
+
+	fail := func(message string) *ProcessRecord {
+		record.Result = ResultFailed
+		record.Message = message
+		return record
+	}
+
+	if entry.Target == "" {
+		return fail(i18n.T("error.plan_no_target"))
+	}
+	if !entry.Transfer.Valid() {
+		return fail(i18n.Tf("error.plan_invalid_transfer", string(entry.Transfer)))
+	}
+	switch entry.Action {
+	case ActionTransfer, ActionRename, ActionOverwrite:
+	default:
+		return fail(i18n.Tf("error.plan_invalid_action", string(entry.Action)))
+	}
+
+	// 检查源文件是否变化
+	if err := checkDrift(ctx, file, entry); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fail(i18n.Tf("error.source_changed", err.Error()))
+	}
+
+	// 只有覆盖操作可以写入已存在的目标
+	if entry.Action != ActionOverwrite {
+		if _, err := os.Lstat(entry.Target); err == nil {
+			return fail(i18n.T("error.target_exists"))
+		}
+	}
+
+	mode, err := p.transferFile(ctx, file, entry.Transfer)
+	record.Transfer = mode
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	if err != nil {
+		return fail(transferErrorMessage(mode, err))
+	}
+
+	record.Result = ResultSuccess
+	record.Message = i18n.T("message.success")
+	return record
+}
+
+// checkDrift 比较源文件当前状态与计划记录
+func checkDrift(ctx context.Context, file *FileInfo, entry PlanEntry) error {
+	info, err := os.Stat(entry.Source)
+	if err != nil {
+		return err
+	}
+	if info.Size() != entry.Size {
+		return fmt.Errorf("大小 %d → %d", entry.Size, info.Size())
+	}
+	if !info.ModTime().Equal(entry.ModTime) {
+		return fmt.Errorf("修改时间 %s → %s",
+			entry.ModTime.Format(time.RFC3339), info.ModTime().Format(time.RFC3339))
+	}
+
+	if entry.Digest != "" {
+		hasher, err := entry.Digest.hasher()
+		if err != nil {
+			return err
+		}
+		digest, err := CalculateDigest(ctx, entry.Source, hasher)
+		if err != nil {
+			return err
+		}
+		if digest != entry.Digest {
+			return fmt.Errorf("%s %s → %s", entry.Digest.Algorithm(), entry.Digest.Hex(), digest.Hex())
+		}
+		file.Digest = digest
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package organizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestPlanApply(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	os.MkdirAll(source, 0755)
+	for name, content := range map[string]string{
+		"IMG_20210304_101530.jpg": "first",
+		"IMG_20210305_101530.jpg": "second",
+		"IMG_20210306_101530.jpg": "third",
+	} {
+		os.WriteFile(filepath.Join(source, name), []byte(content), 0644)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.SourceDir = source
+	cfg.TargetDir = filepath.Join(dir, "target")
+	cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+	cfg.DryRun = true
+
+	files, err := NewScanner(source).Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	processor := NewProcessor(cfg)
+	var records []ProcessRecord
+	for _, file := range files {
+		record, _ := processor.Process(context.Background(), file)
+		records = append(records, *record)
+	}
+
+	plan, err := NewPlan(context.Background(), source, records, processor.Hasher())
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	path := filepath.Join(dir, "plan.json")
+	if err := plan.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadPlan(path)
+	if err != nil || len(loaded.Entries) != 3 {
+		t.Fatalf("LoadPlan() = %v, %v", loaded, err)
+	}
+
+	// 手动编辑: 第二个文件改到其他目录，第三个文件在计划后被修改（大小与修改时间不变）
+	loaded.Entries[1].Target = filepath.Join(dir, "elsewhere", "renamed.jpg")
+	third := loaded.Entries[2]
+	os.WriteFile(third.Source, []byte("THIRD"), 0644)
+	os.Chtimes(third.Source, third.ModTime, third.ModTime)
+
+	cfg.DryRun = false
+	applier := NewProcessor(cfg)
+	expected := []ProcessResult{ResultSuccess, ResultSuccess, ResultFailed}
+	for i, entry := range loaded.Entries {
+		record := applier.Apply(context.Background(), entry)
+		if record.Result != expected[i] {
+			t.Errorf("Apply(%s) = %s (%s), want %s", entry.Source, record.Result, record.Message, expected[i])
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "elsewhere", "renamed.jpg")); err != nil {
+		t.Errorf("edited target was not used: %v", err)
+	}
+	if _, err := os.Stat(third.Target); !os.IsNotExist(err) {
+		t.Errorf("drifted source was applied")
+	}
+
+	// 再次执行: 目标已存在，非覆盖操作应失败
+	if record := applier.Apply(context.Background(), loaded.Entries[0]); record.Result != ResultFailed {
+		t.Errorf("Apply() over existing target = %s, want failed", record.Result)
+	}
+}
@@ -1,14 +1,19 @@
 package organizer
 
 import (
-	"crypto/md5"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/chiyiangel/media-organizer-v2/internal/config"
 	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
+	"github.com/chiyiangel/media-organizer-v2/internal/pathtemplate"
 )
 
 // Processor 文件处理器
@@ -16,21 +21,81 @@ type Processor struct {
 	config            *config.Config
 	metadataExtractor *MetadataExtractor
 	duplicateDetector *DuplicateDetector
+	folderLocation    *time.Location // 归档时区，nil 表示拍摄地时间
+	locationErr       error          // 归档时区加载失败的原因，生成目标路径时报告
+	templates         map[FileType]*pathtemplate.Template
+	rename            *pathtemplate.Template // 文件重命名模式，nil 表示保留原文件名
+	sequences         map[string]int         // 序号计数（键为序号置零后的目标路径）
+	planned           map[string]string      // 本次运行已计划的目标路径（目标 → 源文件）
+	journal           *Journal               // 操作日志，nil 表示不记录
+	dirMu             sync.Mutex             // 保护目录创建与记录
 }
 
 // NewProcessor 创建处理器
 func NewProcessor(cfg *config.Config) *Processor {
+	// 路径模板已在配置校验时检查，此处忽略错误
+	// 时区加载失败时不能退回拍摄地时间，保留错误让每个文件都失败
+	location, locationErr := cfg.FolderLocation()
+	templates := make(map[FileType]*pathtemplate.Template)
+	for _, fileType := range []FileType{FileTypePhoto, FileTypeVideo} {
+		template, err := cfg.TemplateFor(string(fileType))
+		if err != nil {
+			template = pathtemplate.MustParse(pathtemplate.DefaultLayout)
+		}
+		templates[fileType] = template
+	}
+	rename, _ := cfg.RenameTemplate()
+
 	return &Processor{
 		config:            cfg,
-		metadataExtractor: NewMetadataExtractor(),
+		metadataExtractor: NewMetadataExtractor(cfg),
 		duplicateDetector: NewDuplicateDetector(cfg),
+		folderLocation:    location,
+		locationErr:       locationErr,
+		templates:         templates,
+		rename:            rename,
+		sequences:         make(map[string]int),
+		planned:           make(map[string]string),
 	}
 }
 
 // Process 处理文件
-func (p *Processor) Process(file *FileInfo) (*ProcessRecord, error) {
+// 依次执行准备、分配、传输三个阶段；并发处理见 ProcessAll
+// ctx 取消时在数据块边界停止并删除未完成的目标，返回 nil 记录和 ctx.Err()
+func (p *Processor) Process(ctx context.Context, file *FileInfo) (*ProcessRecord, error) {
+	if file.SourceDuplicateOf != "" {
+		return sourceDuplicateRecord(file), nil
+	}
+	if record, err := p.prepare(ctx, file); record != nil || err != nil {
+		return record, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	action, record, err := p.assign(ctx, file, nil)
+	if record != nil || err != nil {
+		return record, err
+	}
+	return p.execute(ctx, file, action)
+}
+
+// prepare 读取文件元数据（日期、相机信息、模板需要的哈希）
+// 只读取源文件，不依赖其他文件，可以并发执行；返回非 nil 的记录表示处理已结束
+func (p *Processor) prepare(ctx context.Context, file *FileInfo) (*ProcessRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// 提取日期
 	date, err := p.metadataExtractor.ExtractDate(file)
+	if errors.Is(err, ErrNoDate) {
+		// 没有可靠日期（例如禁用了修改时间回退）
+		return &ProcessRecord{
+			File:    file,
+			Result:  ResultSkipped,
+			Message: i18n.T("message.undated"),
+		}, nil
+	}
 	if err != nil {
 		return &ProcessRecord{
 			File:    file,
@@ -40,80 +105,386 @@ func (p *Processor) Process(file *FileInfo) (*ProcessRecord, error) {
 	}
 	file.Date = date
 
+	// 预先读取模板需要的相机信息和哈希
+	if template, ok := p.templates[file.Type]; ok {
+		if p.usesToken(template, "camera", "make", "model") {
+			p.metadataExtractor.ExtractCamera(file)
+		}
+		if p.usesToken(template, "hash") {
+			if _, err = file.fullHash(ctx, p.Hasher()); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				return &ProcessRecord{
+					File:    file,
+					Result:  ResultFailed,
+					Message: i18n.Tf("error.target_path", err.Error()),
+				}, err
+			}
+		}
+	}
+
+	// 感知哈希模式：解码照片较慢，在准备阶段完成，分配阶段只比较哈希
+	if err := p.duplicateDetector.PreparePerceptual(ctx, file); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// sourceDuplicateRecord 源目录中的重复文件只导入一份，其余记录为跳过
+func sourceDuplicateRecord(file *FileInfo) *ProcessRecord {
+	return &ProcessRecord{
+		File:        file,
+		Result:      ResultSkipped,
+		Message:     i18n.Tf("message.source_duplicate", file.SourceDuplicateOf),
+		Action:      ActionSkip,
+		DuplicateOf: file.SourceDuplicateOf,
+	}
+}
+
+// keepsContent 判断处理记录是否保证了文件内容在目标库中有一份
+// 成功导入，或因目标库中已有相同（相似）内容而跳过
+func keepsContent(record *ProcessRecord) bool {
+	if record == nil {
+		return false
+	}
+	return record.Result == ResultSuccess || (record.Result == ResultSkipped && record.DuplicateOf != "")
+}
+
+// assign 分配目标路径并检查重复，确定要执行的操作
+// 会读写序号和已计划路径，必须按文件顺序逐个执行；返回非 nil 的记录表示处理已结束
+// wait 不为 nil 时，在检查重复前等待仍在传输到同一目标的文件完成
+func (p *Processor) assign(ctx context.Context, file *FileInfo, wait func(target string)) (PlanAction, *ProcessRecord, error) {
+	defer func() { file.tiers = p.duplicateDetector.tiers }()
+
 	// 生成目标路径
-	targetPath := p.generateTargetPath(file)
+	targetPath, err := p.generateTargetPath(ctx, file)
+	if ctx.Err() != nil {
+		return "", nil, ctx.Err()
+	}
+	if err != nil {
+		return "", &ProcessRecord{
+			File:    file,
+			Result:  ResultFailed,
+			Message: i18n.Tf("error.target_path", err.Error()),
+		}, err
+	}
 	file.TargetPath = targetPath
+	if wait != nil {
+		wait(targetPath)
+	}
+
+	// 感知哈希模式：在整个目标库中查找相似照片
+	if record, err := p.checkSimilar(ctx, file, wait); record != nil || err != nil {
+		return ActionSkip, record, err
+	}
 
-	// 检查重复
-	isDuplicate, err := p.duplicateDetector.IsDuplicate(file)
+	// 检查重复（包括本次运行中已计划的目标）
+	isDuplicate, duplicateOf, err := p.checkDuplicate(ctx, file)
+	if ctx.Err() != nil {
+		return "", nil, ctx.Err()
+	}
 	if err != nil {
-		return &ProcessRecord{
+		return "", &ProcessRecord{
 			File:    file,
 			Result:  ResultFailed,
 			Message: i18n.Tf("error.check_duplicate", err.Error()),
 		}, err
 	}
 
+	action := ActionTransfer
 	if isDuplicate {
-		switch p.config.DuplicateStrategy {
+		// 副本位于目标库的其他路径时没有可重命名或覆盖的目标，总是跳过
+		strategy := p.config.DuplicateStrategy
+		if duplicateOf != file.TargetPath {
+			strategy = config.StrategySkip
+		}
+		switch strategy {
 		case config.StrategySkip:
-			return &ProcessRecord{
-				File:    file,
-				Result:  ResultSkipped,
-				Message: i18n.T("message.duplicate_skipped"),
+			message := i18n.T("message.duplicate_skipped")
+			if p.config.DuplicateDetection == config.DetectionLibrary {
+				message = i18n.Tf("message.library_duplicate", duplicateOf)
+			}
+			return ActionSkip, &ProcessRecord{
+				File:        file,
+				Result:      ResultSkipped,
+				Message:     message,
+				Action:      ActionSkip,
+				DuplicateOf: duplicateOf,
 			}, nil
 
 		case config.StrategyOverwrite:
 			// 继续处理，覆盖文件
+			action = ActionOverwrite
 
 		case config.StrategyRename:
 			// 重命名文件
-			targetPath = p.generateUniqueTargetPath(file)
-			file.TargetPath = targetPath
+			file.TargetPath = p.generateUniqueTargetPath(file.TargetPath)
+			action = ActionRename
 		}
+	} else if p.targetTaken(file.TargetPath) {
+		// 目标已存在但内容不同（内容哈希模式），将被覆盖
+		action = ActionOverwrite
+	}
+	p.planned[file.TargetPath] = file.Path
+	p.duplicateDetector.AddToLibrary(file)
+
+	// 演练模式：只生成计划，不修改文件系统
+	if p.config.DryRun {
+		return action, &ProcessRecord{
+			File:        file,
+			Result:      ResultSuccess,
+			Message:     i18n.T("message.planned"),
+			Action:      action,
+			Transfer:    p.transferMode(),
+			DuplicateOf: file.similarPhoto(),
+		}, nil
 	}
 
-	// 复制文件
-	if err := p.copyFile(file.Path, file.TargetPath); err != nil {
+	return action, nil, nil
+}
+
+// execute 将文件传输到已分配的目标路径
+// 不同文件的传输可以并发执行
+func (p *Processor) execute(ctx context.Context, file *FileInfo, action PlanAction) (*ProcessRecord, error) {
+	// 传输文件
+	mode, err := p.transferFile(ctx, file, p.transferMode())
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		// 已取消，未完成的目标已删除
+		return nil, err
+	}
+	if err != nil {
 		return &ProcessRecord{
-			File:    file,
-			Result:  ResultFailed,
-			Message: i18n.Tf("error.copy_file", err.Error()),
+			File:     file,
+			Result:   ResultFailed,
+			Message:  transferErrorMessage(mode, err),
+			Action:   action,
+			Transfer: mode,
 		}, err
 	}
 
+	message := i18n.T("message.success")
+	switch {
+	case file.replaces != "" && p.journal == nil:
+		// 没有操作日志时无法撤销替换，保留较低分辨率的照片，只标记相似
+		file.SimilarTo = file.replaces
+		message = i18n.Tf("message.similar_imported", file.SimilarTo)
+	case file.replaces != "":
+		if err := p.retireReplaced(file.replaces); err != nil {
+			file.SimilarTo = file.replaces
+			message = i18n.Tf("error.similar_replace", file.replaces, err.Error())
+		} else {
+			file.Replaces = file.replaces
+			message = i18n.Tf("message.similar_replaced", file.Replaces)
+		}
+	case file.SimilarTo != "":
+		message = i18n.Tf("message.similar_imported", file.SimilarTo)
+	}
+
 	return &ProcessRecord{
-		File:    file,
-		Result:  ResultSuccess,
-		Message: i18n.T("message.success"),
+		File:        file,
+		Result:      ResultSuccess,
+		Message:     message,
+		Action:      action,
+		Transfer:    mode,
+		DuplicateOf: file.similarPhoto(),
 	}, nil
 }
 
-// generateTargetPath 生成目标路径
-// 目录结构: YYYY/MM/MM-DD (月份-日期)
-// 例如: 2025/10/10-01, 2025/10/10-25, 2025/12/12-31
-func (p *Processor) generateTargetPath(file *FileInfo) string {
-	year := file.Date.Format("2006")
-	month := file.Date.Format("01")
-	day := file.Date.Format("02")
+// transferErrorMessage 生成传输失败的消息，写入目标失败和校验失败单独说明
+func transferErrorMessage(mode config.TransferMode, err error) string {
+	var writeErr *TargetWriteError
+	if errors.As(err, &writeErr) {
+		return i18n.Tf("error.target_write", writeErr.Error())
+	}
+	var verifyErr *VerifyError
+	if errors.As(err, &verifyErr) {
+		return i18n.Tf("error.verify_failed", verifyErr.Error())
+	}
+	return i18n.Tf("error.transfer_file", string(mode), err.Error())
+}
+
+// checkDuplicate 检查是否已有重复文件，返回已有文件的位置
+// 目标尚未写入但已在本次运行中计划时，与计划写入的源文件比较；
+// 目标库内容索引模式下还会在整个目标库中查找
+func (p *Processor) checkDuplicate(ctx context.Context, file *FileInfo) (bool, string, error) {
+	if p.config.DuplicateDetection == config.DetectionLibrary {
+		existing, err := p.duplicateDetector.FindInLibrary(ctx, file)
+		if err != nil || existing == "" {
+			return false, "", err
+		}
+		return true, existing, nil
+	}
+
+	var isDuplicate bool
+	var err error
+	if _, statErr := os.Stat(file.TargetPath); statErr == nil {
+		isDuplicate, err = p.duplicateDetector.IsDuplicate(ctx, file)
+	} else if source, ok := p.planned[file.TargetPath]; ok {
+		isDuplicate, err = p.duplicateDetector.IsDuplicateOf(ctx, file, source)
+	}
+	if !isDuplicate || err != nil {
+		return false, "", err
+	}
+	return true, file.TargetPath, nil
+}
+
+// checkSimilar 在目标库中查找与照片相似的照片，返回非 nil 的记录表示跳过
+// 保留较高分辨率版本时只保留像素较多的一张：新照片较大时记录要替换的照片，否则跳过新照片；
+// 否则跳过策略下跳过新照片，其他策略仍导入并标记相似的照片
+func (p *Processor) checkSimilar(ctx context.Context, file *FileInfo, wait func(target string)) (*ProcessRecord, error) {
+	match, err := p.duplicateDetector.FindSimilar(ctx, file)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return &ProcessRecord{
+			File:    file,
+			Result:  ResultFailed,
+			Message: i18n.Tf("error.check_duplicate", err.Error()),
+		}, err
+	}
+	if match == nil {
+		return nil, nil
+	}
+
+	skip := func(message string) *ProcessRecord {
+		return &ProcessRecord{
+			File:        file,
+			Result:      ResultSkipped,
+			Message:     message,
+			Action:      ActionSkip,
+			DuplicateOf: match.Path,
+		}
+	}
+	if p.config.KeepHigherResolution {
+		if !p.duplicateDetector.KeepsNew(file, match) {
+			return skip(i18n.Tf("message.similar_lower", match.Path)), nil
+		}
+		// 同一目标路径由覆盖处理；其他位置的照片可能仍在传输，替换前等待完成
+		if match.Path != file.TargetPath {
+			if wait != nil {
+				wait(match.Path)
+			}
+			file.replaces = match.Path
+		}
+		return nil, nil
+	}
+	if p.config.DuplicateStrategy == config.StrategySkip {
+		return skip(i18n.Tf("message.similar_skipped", match.Path, match.Distance)), nil
+	}
+	file.SimilarTo = match.Path
+	return nil, nil
+}
 
-	// 格式化为 MM-DD 格式
-	monthDay := fmt.Sprintf("%s-%s", month, day)
-	targetDir := filepath.Join(p.config.TargetDir, year, month, monthDay)
-	return filepath.Join(targetDir, file.Name)
+// targetTaken 判断目标路径是否已存在或已被本次运行计划占用
+func (p *Processor) targetTaken(path string) bool {
+	if _, ok := p.planned[path]; ok {
+		return true
+	}
+	_, err := os.Lstat(path)
+	return err == nil
 }
 
-// generateUniqueTargetPath 生成唯一目标路径
-func (p *Processor) generateUniqueTargetPath(file *FileInfo) string {
-	basePath := p.generateTargetPath(file)
+// generateTargetPath 按路径模板生成目标路径
+// 默认模板的目录结构: YYYY/MM/MM-DD (月份-日期)
+// 例如: 2025/10/10-01, 2025/10/10-25, 2025/12/12-31
+// 照片和视频可分别配置目标目录与模板；配置了重命名模式时，文件名由重命名模式生成
+func (p *Processor) generateTargetPath(ctx context.Context, file *FileInfo) (string, error) {
+	template, ok := p.templates[file.Type]
+	if !ok {
+		return "", fmt.Errorf("不支持的文件类型")
+	}
+	if p.locationErr != nil {
+		return "", fmt.Errorf("无效的归档时区 %s: %w", p.config.FolderTimezone, p.locationErr)
+	}
+
+	if p.usesToken(template, "camera", "make", "model") {
+		p.metadataExtractor.ExtractCamera(file)
+	}
+
 	ext := filepath.Ext(file.Name)
-	nameWithoutExt := file.Name[:len(file.Name)-len(ext)]
+	date := p.localDate(file.Date)
+	values := &pathtemplate.Values{
+		Date:      date,
+		Bucket:    p.folderDate(date),
+		Name:      file.Name[:len(file.Name)-len(ext)],
+		Ext:       ext,
+		Type:      string(file.Type),
+		Make:      file.CameraMake,
+		Model:     file.CameraModel,
+		Subfolder: file.Subfolder,
+		Hash: func() (string, error) {
+			digest, err := file.fullHash(ctx, p.Hasher())
+			return digest.Hex(), err
+		},
+	}
+
+	root := p.config.TargetDirFor(string(file.Type))
+	rel, err := p.renderPath(template, values)
+	if err != nil {
+		return "", err
+	}
+
+	// 序号按处理顺序递增，同一目标路径（例如同一秒的连拍）依次为 1、2、3...
+	if p.usesToken(template, "seq") {
+		key := filepath.Join(root, filepath.FromSlash(rel))
+		p.sequences[key]++
+		values.Seq = p.sequences[key]
+		file.seqKey, file.seq = key, values.Seq
+		if rel, err = p.renderPath(template, values); err != nil {
+			return "", err
+		}
+	}
+
+	return filepath.Join(root, filepath.FromSlash(rel)), nil
+}
+
+// renderPath 渲染相对目标路径
+// 重命名模式与路径模板使用相同的取值，文件名中的时间是拍摄时间，不受一天起始小时影响
+func (p *Processor) renderPath(template *pathtemplate.Template, values *pathtemplate.Values) (string, error) {
+	rel, err := template.Execute(values)
+	if err != nil || p.rename == nil {
+		return rel, err
+	}
+
+	name, err := p.rename.Execute(values)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(path.Dir(rel), name), nil
+}
+
+// usesToken 判断路径模板或重命名模式是否使用了指定占位符
+func (p *Processor) usesToken(template *pathtemplate.Template, names ...string) bool {
+	return template.Uses(names...) || (p.rename != nil && p.rename.Uses(names...))
+}
+
+// localDate 将拍摄时间换算到配置的归档时区
+func (p *Processor) localDate(t time.Time) time.Time {
+	if p.folderLocation != nil {
+		t = t.In(p.folderLocation)
+	}
+	return t
+}
+
+// folderDate 计算用于归档的日期
+// 按一天的起始小时调整（例如凌晨2点的照片归入前一天），只影响年、月、日占位符和目录中的 {date}
+func (p *Processor) folderDate(t time.Time) time.Time {
+	return t.Add(-time.Duration(p.config.DayStartHour) * time.Hour)
+}
+
+// generateUniqueTargetPath 在目标文件名后追加序号，生成唯一目标路径
+func (p *Processor) generateUniqueTargetPath(basePath string) string {
+	name := filepath.Base(basePath)
+	ext := filepath.Ext(name)
+	nameWithoutExt := name[:len(name)-len(ext)]
 
 	counter := 1
 	newPath := basePath
 
 	for {
-		if _, err := os.Stat(newPath); os.IsNotExist(err) {
+		if !p.targetTaken(newPath) {
 			return newPath
 		}
 		newPath = filepath.Join(
@@ -125,43 +496,83 @@ func (p *Processor) generateUniqueTargetPath(file *FileInfo) string {
 }
 
 // copyFile 复制文件
-func (p *Processor) copyFile(src, dst string) error {
-	// 创建目标目录
-	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
-		return err
-	}
+// 先写入目标目录中的临时文件并落盘，再重命名到最终路径，
+// 避免崩溃或磁盘已满时在最终路径留下不完整的文件
+// 开启校验时在重命名前重新读取临时文件（读取前尽力丢弃页缓存，见 verifyCopy）
+func (p *Processor) copyFile(ctx context.Context, file *FileInfo) error {
+	dst := file.TargetPath
 
 	// 打开源文件
-	srcFile, err := os.Open(src)
+	srcFile, err := os.Open(file.Path)
 	if err != nil {
 		return err
 	}
 	defer srcFile.Close()
-
-	// 创建目标文件
-	dstFile, err := os.Create(dst)
+	srcInfo, err := srcFile.Stat()
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
 
-	// 复制
-	_, err = io.Copy(dstFile, srcFile)
-	return err
-}
+	// 创建临时文件
+	tmp, err := p.createTempFile(dst)
+	if err != nil {
+		return err
+	}
 
-// CalculateMD5 计算文件MD5
-func CalculateMD5(path string) (string, error) {
-	file, err := os.Open(path)
+	// 复制并设置属性（在重命名前完成，最终路径上的文件总是完整的）
+	// 复制的同时计算源数据的哈希，用于校验和后续的重复检测
+	hasher := p.Hasher()
+	hash := hasher.New()
+	// 取消时在数据块边界停止，下面会删除临时文件，最终路径不受影响
+	_, err = io.Copy(io.MultiWriter(targetWriter{tmp}, hash), contextReader{ctx, srcFile})
+	if err == nil {
+		if chmodErr := tmp.Chmod(p.targetMode(srcInfo)); chmodErr != nil {
+			err = &TargetWriteError{Path: dst, Err: chmodErr}
+		}
+	}
+	if err == nil {
+		err = p.applyAttributes(file, srcInfo, tmp.Name())
+	}
 	if err != nil {
-		return "", err
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
 	}
-	defer file.Close()
 
-	hash := md5.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", err
+	sum := newDigest(hasher, hash.Sum(nil))
+	var verify func(path string) error
+	if p.config.Verify {
+		verify = func(path string) error { return verifyCopy(ctx, path, sum) }
+	}
+	if err := commitTempFile(tmp, dst, verify); err != nil {
+		os.Remove(tmp.Name())
+		return err
 	}
 
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	file.Digest = sum
+	return nil
+}
+
+// Hasher 返回计算内容摘要使用的算法
+func (p *Processor) Hasher() Hasher {
+	return p.duplicateDetector.hasher
+}
+
+// HashTiers 返回重复检测中分级比较内容的计数
+// 只能在处理结束后调用
+func (p *Processor) HashTiers() HashTiers {
+	return p.duplicateDetector.Tiers()
+}
+
+// contextReader 每次读取前检查 ctx，使 io.Copy 在数据块边界响应取消
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(b []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(b)
 }
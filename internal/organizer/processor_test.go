@@ -0,0 +1,288 @@
+package organizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestGenerateTargetPathTimezone(t *testing.T) {
+	tokyo := time.FixedZone("", 9*3600)
+
+	tests := []struct {
+		name     string
+		timezone string
+		dayStart int
+		date     time.Time
+		expected string
+	}{
+		{"Source local", "", 0, time.Date(2021, 3, 4, 1, 0, 0, 0, tokyo), "2021/03/03-04"},
+		{"UTC", "utc", 0, time.Date(2021, 3, 4, 1, 0, 0, 0, tokyo), "2021/03/03-03"},
+		{"Named zone", "America/New_York", 0, time.Date(2021, 3, 4, 12, 0, 0, 0, time.UTC), "2021/03/03-04"},
+		{"Day starts at 4", "", 4, time.Date(2021, 3, 4, 2, 0, 0, 0, tokyo), "2021/03/03-03"},
+		{"After day start", "", 4, time.Date(2021, 3, 4, 5, 0, 0, 0, tokyo), "2021/03/03-04"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.TargetDir = "target"
+			cfg.FolderTimezone = tt.timezone
+			cfg.DayStartHour = tt.dayStart
+
+			file := &FileInfo{Name: "IMG_0001.jpg", Type: FileTypePhoto, Date: tt.date}
+			result, err := NewProcessor(cfg).generateTargetPath(context.Background(), file)
+			if err != nil {
+				t.Fatalf("generateTargetPath() error = %v", err)
+			}
+			expected := filepath.Join("target", filepath.FromSlash(tt.expected), "IMG_0001.jpg")
+			if result != expected {
+				t.Errorf("generateTargetPath() = %s, want %s", result, expected)
+			}
+		})
+	}
+}
+
+func TestGenerateTargetPathInvalidTimezone(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = "target"
+	cfg.FolderTimezone = "Not/AZone"
+
+	// 时区无法加载时不能静默使用拍摄地时间
+	file := &FileInfo{Name: "IMG_0001.jpg", Type: FileTypePhoto, Date: time.Date(2021, 3, 4, 10, 15, 30, 0, time.UTC)}
+	if result, err := NewProcessor(cfg).generateTargetPath(context.Background(), file); err == nil {
+		t.Errorf("generateTargetPath() = %s, want error", result)
+	}
+}
+
+func TestGenerateTargetPathDayStart(t *testing.T) {
+	// 一天从 4 点开始只影响归档日，时间占位符、{date} 和重命名仍使用拍摄时间
+	tests := []struct {
+		name     string
+		template string
+		rename   string
+		date     time.Time
+		expected string
+	}{
+		{"Hour in template", "{year}/{month}/{month}-{day}/{hour}{minute}_{name}{ext}", "",
+			time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local), "2021/03/03-04/1015_IMG_0001.jpg"},
+		{"Rename pattern", "", "{date:20060102_150405}{ext}",
+			time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local), "2021/03/03-04/20210304_101530.jpg"},
+		{"Before day start", "{year}/{month}/{month}-{day}/{hour}_{name}{ext}", "{date:20060102_150405}{ext}",
+			time.Date(2021, 3, 4, 2, 15, 30, 0, time.Local), "2021/03/03-03/20210304_021530.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.TargetDir = "target"
+			cfg.DayStartHour = 4
+			cfg.PathTemplate = tt.template
+			cfg.RenamePattern = tt.rename
+
+			file := &FileInfo{Name: "IMG_0001.jpg", Type: FileTypePhoto, Date: tt.date}
+			result, err := NewProcessor(cfg).generateTargetPath(context.Background(), file)
+			if err != nil {
+				t.Fatalf("generateTargetPath() error = %v", err)
+			}
+			if expected := filepath.Join("target", filepath.FromSlash(tt.expected)); result != expected {
+				t.Errorf("generateTargetPath() = %s, want %s", result, expected)
+			}
+		})
+	}
+}
+
+func TestGenerateTargetPathTemplate(t *testing.T) {
+	date := time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		template string
+		file     *FileInfo
+		expected string
+	}{
+		{"Default", "", &FileInfo{Name: "IMG_0001.jpg", Type: FileTypePhoto},
+			"2021/03/03-04/IMG_0001.jpg"},
+		{"Month name and camera", "{year}/{month:02}-{monthname}/{date:2006-01-02}_{camera}/{name}{ext}",
+			&FileInfo{Name: "IMG_0001.JPG", Type: FileTypePhoto, CameraMake: "Canon", CameraModel: "Canon EOS R5"},
+			"2021/03-March/2021-03-04_Canon EOS R5/IMG_0001.JPG"},
+		{"Type and subfolder", "{type}/{subfolder}/{year}/{name}{ext:lower}",
+			&FileInfo{Name: "clip.MOV", Type: FileTypeVideo, Subfolder: "Trips/Japan"},
+			"video/Trips/Japan/2021/clip.mov"},
+		{"Empty subfolder", "{subfolder}/{year}/{name}{ext}",
+			&FileInfo{Name: "IMG_0001.jpg", Type: FileTypePhoto},
+			"2021/IMG_0001.jpg"},
+		{"Hash prefix", "{year}/{hash:6}{ext}",
+			&FileInfo{Name: "IMG_0001.jpg", Type: FileTypePhoto, Digest: "md5:0123456789abcdef"},
+			"2021/012345.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.TargetDir = "target"
+			cfg.PathTemplate = tt.template
+
+			tt.file.Date = date
+			result, err := NewProcessor(cfg).generateTargetPath(context.Background(), tt.file)
+			if err != nil {
+				t.Fatalf("generateTargetPath() error = %v", err)
+			}
+			expected := filepath.Join("target", filepath.FromSlash(tt.expected))
+			if result != expected {
+				t.Errorf("generateTargetPath() = %s, want %s", result, expected)
+			}
+		})
+	}
+}
+
+func TestGenerateTargetPathRename(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = "target"
+	cfg.RenamePattern = "{date:20060102_150405}_{seq:03}{ext:lower}"
+	processor := NewProcessor(cfg)
+
+	burst := time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local)
+	files := []struct {
+		file     *FileInfo
+		expected string
+	}{
+		{&FileInfo{Name: "DSC00012.ARW", Type: FileTypePhoto, Date: burst}, "2021/03/03-04/20210304_101530_001.arw"},
+		{&FileInfo{Name: "DSC00013.ARW", Type: FileTypePhoto, Date: burst}, "2021/03/03-04/20210304_101530_002.arw"},
+		{&FileInfo{Name: "DSC00014.ARW", Type: FileTypePhoto, Date: burst.Add(time.Second)}, "2021/03/03-04/20210304_101531_001.arw"},
+		{&FileInfo{Name: "DSC00001.JPG", Type: FileTypePhoto, Date: burst}, "2021/03/03-04/20210304_101530_001.jpg"},
+	}
+
+	for _, tt := range files {
+		result, err := processor.generateTargetPath(context.Background(), tt.file)
+		if err != nil {
+			t.Fatalf("generateTargetPath() error = %v", err)
+		}
+		expected := filepath.Join("target", filepath.FromSlash(tt.expected))
+		if result != expected {
+			t.Errorf("generateTargetPath(%s) = %s, want %s", tt.file.Name, result, expected)
+		}
+	}
+}
+
+func TestGenerateTargetPathRenameDayStart(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = "target"
+	cfg.DayStartHour = 4
+	cfg.RenamePattern = "{date:20060102_150405}_{seq:03}{ext:lower}"
+	processor := NewProcessor(cfg)
+
+	// 凌晨的连拍归入前一天，文件名和序号仍按拍摄时间
+	burst := time.Date(2021, 3, 4, 2, 15, 30, 0, time.Local)
+	files := []struct {
+		file     *FileInfo
+		expected string
+	}{
+		{&FileInfo{Name: "DSC00012.JPG", Type: FileTypePhoto, Date: burst}, "2021/03/03-03/20210304_021530_001.jpg"},
+		{&FileInfo{Name: "DSC00013.JPG", Type: FileTypePhoto, Date: burst}, "2021/03/03-03/20210304_021530_002.jpg"},
+		{&FileInfo{Name: "DSC00014.JPG", Type: FileTypePhoto, Date: burst.Add(time.Second)}, "2021/03/03-03/20210304_021531_001.jpg"},
+		{&FileInfo{Name: "DSC00015.JPG", Type: FileTypePhoto, Date: burst.Add(4 * time.Hour)}, "2021/03/03-04/20210304_061530_001.jpg"},
+	}
+
+	for _, tt := range files {
+		result, err := processor.generateTargetPath(context.Background(), tt.file)
+		if err != nil {
+			t.Fatalf("generateTargetPath() error = %v", err)
+		}
+		expected := filepath.Join("target", filepath.FromSlash(tt.expected))
+		if result != expected {
+			t.Errorf("generateTargetPath(%s) = %s, want %s", tt.file.Name, result, expected)
+		}
+	}
+}
+
+func TestGenerateTargetPathTypeRoots(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = "Library"
+	cfg.VideoTargetDir = "Videos"
+	cfg.VideoPathTemplate = "{year}/{name}{ext}"
+	processor := NewProcessor(cfg)
+
+	date := time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local)
+	tests := []struct {
+		file     *FileInfo
+		expected string
+	}{
+		{&FileInfo{Name: "IMG_0001.jpg", Type: FileTypePhoto, Date: date}, "Library/2021/03/03-04/IMG_0001.jpg"},
+		{&FileInfo{Name: "clip.mp4", Type: FileTypeVideo, Date: date}, "Videos/2021/clip.mp4"},
+	}
+
+	for _, tt := range tests {
+		result, err := processor.generateTargetPath(context.Background(), tt.file)
+		if err != nil {
+			t.Fatalf("generateTargetPath() error = %v", err)
+		}
+		if expected := filepath.FromSlash(tt.expected); result != expected {
+			t.Errorf("generateTargetPath(%s) = %s, want %s", tt.file.Name, result, expected)
+		}
+	}
+}
+
+func TestProcessDryRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		detection config.DuplicateDetection
+		strategy  config.DuplicateStrategy
+		second    []byte
+		result    ProcessResult
+		action    PlanAction
+		target    string
+	}{
+		{"Rename planned collision", config.DetectionFilename, config.StrategyRename, []byte("b"),
+			ResultSuccess, ActionRename, "IMG_20210304_101530(1).jpg"},
+		{"Skip planned duplicate", config.DetectionHash, config.StrategySkip, []byte("a"),
+			ResultSkipped, ActionSkip, "IMG_20210304_101530.jpg"},
+		{"Overwrite planned different content", config.DetectionHash, config.StrategySkip, []byte("b"),
+			ResultSuccess, ActionOverwrite, "IMG_20210304_101530.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := filepath.Join(dir, "target")
+			first := filepath.Join(dir, "a", "IMG_20210304_101530.jpg")
+			second := filepath.Join(dir, "b", "IMG_20210304_101530.jpg")
+			os.MkdirAll(filepath.Dir(first), 0755)
+			os.MkdirAll(filepath.Dir(second), 0755)
+			os.WriteFile(first, []byte("a"), 0644)
+			os.WriteFile(second, tt.second, 0644)
+
+			cfg := config.NewDefaultConfig()
+			cfg.TargetDir = target
+			cfg.DuplicateDetection = tt.detection
+			cfg.DuplicateStrategy = tt.strategy
+			cfg.DateSources = map[string][]string{"photo": {config.DateSourceFilename}}
+			cfg.DryRun = true
+			processor := NewProcessor(cfg)
+
+			record, err := processor.Process(context.Background(), &FileInfo{Path: first, Name: filepath.Base(first), Type: FileTypePhoto})
+			if err != nil || record.Action != ActionTransfer {
+				t.Fatalf("Process(first) = %v, %v", record, err)
+			}
+
+			record, err = processor.Process(context.Background(), &FileInfo{Path: second, Name: filepath.Base(second), Type: FileTypePhoto})
+			if err != nil {
+				t.Fatalf("Process(second) error = %v", err)
+			}
+			if record.Result != tt.result || record.Action != tt.action {
+				t.Errorf("Process(second) = %s/%s, want %s/%s", record.Result, record.Action, tt.result, tt.action)
+			}
+			if got := filepath.Base(record.File.TargetPath); got != tt.target {
+				t.Errorf("Process(second) target = %s, want %s", got, tt.target)
+			}
+
+			if _, err := os.Stat(target); !os.IsNotExist(err) {
+				t.Errorf("dry run created %s", target)
+			}
+		})
+	}
+}
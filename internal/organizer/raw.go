@@ -0,0 +1,148 @@
+package organizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// RAW 格式读取器
+//
+// ARW/DNG/NEF/CR2/PEF 本身就是 TIFF 结构，可直接交给 goexif；
+// 以下格式需要单独处理：
+//   ORF/RW2: TIFF 结构，但使用非标准的魔数（"IIRO"/"IIU\0"）
+//   CR3:     ISO-BMFF 容器，EXIF 位于 moov/uuid 下的 CMT1/CMT2 盒子
+//   RAF:     富士专有头部，EXIF 位于内嵌的 JPEG 预览中
+
+// cr3MetadataUUID CR3 元数据 uuid 盒子的用户类型
+var cr3MetadataUUID = []byte{
+	0x85, 0xc0, 0xb6, 0x87, 0x82, 0x0f, 0x11, 0xe0,
+	0x81, 0x11, 0xf4, 0xce, 0x46, 0x2b, 0x6a, 0x48,
+}
+
+// rafMagic RAF 文件头
+const rafMagic = "FUJIFILMCCD-RAW "
+
+// maxCR3ExifSize CMT 盒子最大长度
+const maxCR3ExifSize = 16 << 20
+
+var errNoRawExif = errors.New("RAW文件中没有EXIF数据")
+
+// exifReader 按格式从文件中读取EXIF
+type exifReader func(f *os.File, size int64) (*exif.Exif, error)
+
+// exifReaders 需要特殊处理的扩展名
+var exifReaders = map[string]exifReader{
+	".heic": decodeHEIFExif,
+	".heif": decodeHEIFExif,
+	".cr3":  decodeCR3Exif,
+	".raf":  decodeRAFExif,
+	".orf":  decodePatchedTIFFExif,
+	".rw2":  decodePatchedTIFFExif,
+}
+
+// decodeExifFrom 解码EXIF，忽略子IFD中的非致命错误
+func decodeExifFrom(r io.Reader) (*exif.Exif, error) {
+	x, err := exif.Decode(r)
+	if err != nil && x != nil && !exif.IsCriticalError(err) {
+		return x, nil
+	}
+	return x, err
+}
+
+// decodeHEIFExif 读取 HEIF/HEIC 中的EXIF
+func decodeHEIFExif(f *os.File, size int64) (*exif.Exif, error) {
+	data, err := readHEIFExif(f, size)
+	if err != nil {
+		return nil, err
+	}
+	return decodeExifFrom(bytes.NewReader(data))
+}
+
+// decodePatchedTIFFExif 读取使用非标准魔数的 TIFF 类 RAW（ORF/RW2）
+func decodePatchedTIFFExif(f *os.File, size int64) (*exif.Exif, error) {
+	var hdr [4]byte
+	if _, err := f.ReadAt(hdr[:], 0); err != nil {
+		return nil, err
+	}
+
+	// 将魔数替换为标准 TIFF 的 42
+	switch string(hdr[:2]) {
+	case "II":
+		binary.LittleEndian.PutUint16(hdr[2:], 42)
+	case "MM":
+		binary.BigEndian.PutUint16(hdr[2:], 42)
+	default:
+		return nil, errNoRawExif
+	}
+
+	return decodeExifFrom(io.MultiReader(
+		bytes.NewReader(hdr[:]),
+		io.NewSectionReader(f, 4, size-4),
+	))
+}
+
+// decodeCR3Exif 读取 Canon CR3 中的EXIF
+// CMT2 保存 Exif IFD（含 DateTimeOriginal），CMT1 保存 IFD0
+func decodeCR3Exif(f *os.File, size int64) (*exif.Exif, error) {
+	top, _ := readBMFFBoxes(f, 0, size)
+	moov, ok := findBMFFBox(top, "moov")
+	if !ok {
+		return nil, errNoRawExif
+	}
+
+	children, _ := readBMFFChildren(f, moov)
+	for _, box := range children {
+		if box.Type != "uuid" || box.Size() < 16 {
+			continue
+		}
+		userType := make([]byte, 16)
+		if _, err := f.ReadAt(userType, box.Start); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(userType, cr3MetadataUUID) {
+			continue
+		}
+
+		cmts, _ := readBMFFBoxes(f, box.Start+16, box.End)
+		for _, name := range []string{"CMT2", "CMT1"} {
+			cmt, ok := findBMFFBox(cmts, name)
+			if !ok {
+				continue
+			}
+			data, err := readBoxPayload(f, cmt, maxCR3ExifSize)
+			if err != nil {
+				continue
+			}
+			if x, err := decodeExifFrom(bytes.NewReader(data)); err == nil {
+				return x, nil
+			}
+		}
+	}
+
+	return nil, errNoRawExif
+}
+
+// decodeRAFExif 读取 Fujifilm RAF 内嵌 JPEG 预览中的EXIF
+// 头部偏移 84 处为 JPEG 偏移，88 处为 JPEG 长度（均为大端）
+func decodeRAFExif(f *os.File, size int64) (*exif.Exif, error) {
+	var hdr [92]byte
+	if _, err := f.ReadAt(hdr[:], 0); err != nil {
+		return nil, err
+	}
+	if string(hdr[:len(rafMagic)]) != rafMagic {
+		return nil, errNoRawExif
+	}
+
+	offset := int64(binary.BigEndian.Uint32(hdr[84:88]))
+	length := int64(binary.BigEndian.Uint32(hdr[88:92]))
+	if offset <= 0 || length <= 0 || offset+length > size {
+		return nil, errNoRawExif
+	}
+
+	return decodeExifFrom(io.NewSectionReader(f, offset, length))
+}
@@ -0,0 +1,65 @@
+package organizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestExtractPhotoDateRaw(t *testing.T) {
+	tiff := testTIFF("2021:03:04 10:15:30")
+
+	// ORF: TIFF 结构但魔数为 "IIRO"
+	orf := append([]byte("IIRO"), tiff[4:]...)
+
+	// RAF: 富士头部 + 内嵌预览
+	raf := make([]byte, 100)
+	copy(raf, rafMagic)
+	binary.BigEndian.PutUint32(raf[84:88], uint32(len(raf)))
+	binary.BigEndian.PutUint32(raf[88:92], uint32(len(tiff)))
+	raf = append(raf, tiff...)
+
+	// CR3: moov/uuid/CMT2
+	uuid := testBox("uuid", cr3MetadataUUID, testBox("CMT1", []byte("II*\x00")), testBox("CMT2", tiff))
+	cr3 := bytes.Join([][]byte{testBox("ftyp", []byte("crx ")), testBox("moov", uuid)}, nil)
+
+	tests := []struct {
+		name string
+		file string
+		data []byte
+	}{
+		{"Sony ARW", "DSC00012.ARW", tiff},
+		{"Olympus ORF", "P1010001.ORF", orf},
+		{"Fujifilm RAF", "DSCF0001.RAF", raf},
+		{"Canon CR3", "IMG_0001.CR3", cr3},
+	}
+
+	want := time.Date(2021, 3, 4, 10, 15, 30, 0, time.Local)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			// 将修改时间设为其他日期，确保结果来自EXIF
+			mtime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+			if err := os.Chtimes(path, mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+
+			file := &FileInfo{Path: path, Name: filepath.Base(path), Type: FileTypePhoto}
+			got, err := NewMetadataExtractor(config.NewDefaultConfig()).ExtractDate(file)
+			if err != nil {
+				t.Fatalf("ExtractDate() error = %v", err)
+			}
+			if !got.Equal(want) || file.DateSource != DateSourceEXIF {
+				t.Errorf("ExtractDate() = %v, %v, want %v, %v", got, file.DateSource, want, DateSourceEXIF)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build darwin
+
+package organizer
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflinkFile 使用 clonefile 克隆文件（APFS 支持）
+func reflinkFile(src, dst string) error {
+	err := unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW)
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EXDEV) {
+		return errReflinkUnsupported
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package organizer
+
+// reflinkFile 当前平台不支持写时复制克隆
+func reflinkFile(src, dst string) error {
+	return errReflinkUnsupported
+}
@@ -1,6 +1,7 @@
 package organizer
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,7 +9,10 @@ import (
 
 var (
 	// 照片扩展名（小写）
-	photoExtensions = []string{".arw", ".jpg", ".jpeg", ".png", ".heic", ".gif", ".bmp", ".raw"}
+	photoExtensions = []string{".jpg", ".jpeg", ".png", ".heic", ".heif", ".gif", ".bmp"}
+
+	// RAW 扩展名（小写），归为照片
+	rawExtensions = []string{".arw", ".dng", ".nef", ".cr2", ".cr3", ".raf", ".orf", ".rw2", ".pef", ".raw"}
 
 	// 视频扩展名（小写）
 	videoExtensions = []string{".mp4", ".mov", ".avi", ".mkv", ".flv", ".wmv"}
@@ -26,14 +30,17 @@ func NewScanner(sourceDir string) *Scanner {
 	}
 }
 
-// Scan 扫描文件
-func (s *Scanner) Scan() ([]*FileInfo, error) {
+// Scan 扫描文件，ctx 取消时停止并返回 ctx.Err()
+func (s *Scanner) Scan(ctx context.Context) ([]*FileInfo, error) {
 	var files []*FileInfo
 
 	err := filepath.Walk(s.sourceDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		// 跳过目录
 		if info.IsDir() {
@@ -48,10 +55,11 @@ func (s *Scanner) Scan() ([]*FileInfo, error) {
 
 		// 创建文件信息
 		fileInfo := &FileInfo{
-			Path: path,
-			Name: info.Name(),
-			Type: fileType,
-			Size: info.Size(),
+			Path:      path,
+			Name:      info.Name(),
+			Subfolder: s.subfolder(path),
+			Type:      fileType,
+			Size:      info.Size(),
 		}
 
 		files = append(files, fileInfo)
@@ -61,6 +69,15 @@ func (s *Scanner) Scan() ([]*FileInfo, error) {
 	return files, err
 }
 
+// subfolder 计算文件相对源目录的子目录
+func (s *Scanner) subfolder(path string) string {
+	rel, err := filepath.Rel(s.sourceDir, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
 // getFileType 获取文件类型
 func getFileType(path string) FileType {
 	ext := strings.ToLower(filepath.Ext(path))
@@ -71,6 +88,12 @@ func getFileType(path string) FileType {
 		}
 	}
 
+	for _, rawExt := range rawExtensions {
+		if ext == rawExt {
+			return FileTypePhoto
+		}
+	}
+
 	for _, videoExt := range videoExtensions {
 		if ext == videoExt {
 			return FileTypeVideo
@@ -16,6 +16,11 @@ func TestGetFileType(t *testing.T) {
 		{"MOV video", "/path/to/video.mov", FileTypeVideo},
 		{"Other file", "/path/to/document.pdf", FileTypeOther},
 		{"Uppercase extension", "/path/to/photo.JPG", FileTypePhoto},
+		{"HEIC photo", "/path/to/IMG_0001.HEIC", FileTypePhoto},
+		{"Sony RAW", "/path/to/DSC00012.ARW", FileTypePhoto},
+		{"Canon CR3", "/path/to/IMG_0001.CR3", FileTypePhoto},
+		{"Fujifilm RAF", "/path/to/DSCF0001.RAF", FileTypePhoto},
+		{"Nikon NEF", "/path/to/DSC_0001.nef", FileTypePhoto},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,95 @@
+package organizer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// XMP 附属文件
+// 支持 Lightroom 风格（IMG_0001.xmp）和 darktable 风格（IMG_0001.jpg.xmp）
+
+// maxSidecarSize XMP 文件最大读取长度
+const maxSidecarSize = 1 << 20
+
+// xmpDatePatterns 按优先级排列的 XMP 日期属性（匹配属性或元素形式）
+var xmpDatePatterns = func() []*regexp.Regexp {
+	var patterns []*regexp.Regexp
+	for _, property := range []string{"exif:DateTimeOriginal", "photoshop:DateCreated", "xmp:CreateDate"} {
+		name := regexp.QuoteMeta(property)
+		patterns = append(patterns, regexp.MustCompile(name+`\s*=\s*"([^"]+)"|<`+name+`>([^<]+)</`))
+	}
+	return patterns
+}()
+
+// xmpDateLayouts XMP 日期格式（ISO 8601 子集）
+var xmpDateLayouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// sidecarPaths 返回可能的附属文件路径
+func sidecarPaths(path string) []string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	return []string{
+		base + ".xmp",
+		base + ".XMP",
+		path + ".xmp",
+		path + ".XMP",
+	}
+}
+
+// readSidecarDate 从 XMP 附属文件中读取拍摄时间
+func readSidecarDate(path string) (time.Time, bool) {
+	for _, sidecar := range sidecarPaths(path) {
+		f, err := os.Open(sidecar)
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxSidecarSize))
+		f.Close()
+		if err != nil {
+			continue
+		}
+		if t, ok := parseXMPDate(string(data)); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseXMPDate 解析 XMP 内容中的日期（属性或元素形式）
+func parseXMPDate(xmp string) (time.Time, bool) {
+	for _, re := range xmpDatePatterns {
+		match := re.FindStringSubmatch(xmp)
+		if match == nil {
+			continue
+		}
+		value := match[1]
+		if value == "" {
+			value = match[2]
+		}
+		if t, ok := parseXMPDateValue(strings.TrimSpace(value)); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseXMPDateValue 解析 XMP 日期值，不带时区的值按本地时间解析
+func parseXMPDateValue(value string) (time.Time, bool) {
+	for _, layout := range xmpDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,138 @@
+package organizer
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// EXIF 2.31 时区偏移标签（goexif 未映射）
+const (
+	tagOffsetTime          uint16 = 0x9010 // 对应 DateTime
+	tagOffsetTimeOriginal  uint16 = 0x9011 // 对应 DateTimeOriginal
+	tagOffsetTimeDigitized uint16 = 0x9012 // 对应 DateTimeDigitized
+)
+
+// exifOffsetTags 日期字段对应的时区偏移标签
+var exifOffsetTags = map[exif.FieldName]uint16{
+	exif.DateTimeOriginal:  tagOffsetTimeOriginal,
+	exif.DateTimeDigitized: tagOffsetTimeDigitized,
+	exif.DateTime:          tagOffsetTime,
+}
+
+// exifLocation 确定 EXIF 日期字段的时区
+// 优先级: OffsetTime* 标签 → GPS 时间推算 → Canon TimeInfo → 本地时区
+func exifLocation(x *exif.Exif, field exif.FieldName, dateStr string) *time.Location {
+	if id, ok := exifOffsetTags[field]; ok {
+		if tag, ok := exifRawTag(x, id); ok {
+			if value, err := tag.StringVal(); err == nil {
+				if loc, ok := parseExifOffset(value); ok {
+					return loc
+				}
+			}
+		}
+	}
+
+	if local, err := time.ParseInLocation(exifTimeLayout, dateStr, time.UTC); err == nil {
+		if gps, ok := exifGPSTime(x); ok {
+			if loc, ok := gpsZone(local, gps); ok {
+				return loc
+			}
+		}
+	}
+
+	if tz, _ := x.TimeZone(); tz != nil {
+		return tz
+	}
+	return time.Local
+}
+
+// parseExifOffset 解析 "+08:00" 形式的偏移
+func parseExifOffset(value string) (*time.Location, bool) {
+	value = strings.TrimRight(strings.TrimSpace(value), "\x00")
+	t, err := time.Parse("-07:00", value)
+	if err != nil {
+		return nil, false
+	}
+	_, offset := t.Zone()
+	return time.FixedZone("", offset), true
+}
+
+// exifGPSTime 读取 GPS 日期和时间（UTC）
+func exifGPSTime(x *exif.Exif) (time.Time, bool) {
+	dateTag, err := x.Get(exif.GPSDateStamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	dateStr, err := dateTag.StringVal()
+	if err != nil {
+		return time.Time{}, false
+	}
+	date, err := time.Parse("2006:01:02", strings.TrimRight(dateStr, "\x00 "))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	timeTag, err := x.Get(exif.GPSTimeStamp)
+	if err != nil || timeTag.Count < 3 {
+		return time.Time{}, false
+	}
+	var seconds float64
+	for i, unit := range []float64{3600, 60, 1} {
+		num, den, err := timeTag.Rat2(i)
+		if err != nil || den == 0 {
+			return time.Time{}, false
+		}
+		seconds += float64(num) / float64(den) * unit
+	}
+
+	return date.Add(time.Duration(seconds * float64(time.Second))), true
+}
+
+// gpsZone 根据本地时间（按UTC解析的墙上时间）与 GPS UTC 时间推算时区
+// 两者通常相差几秒到几分钟，因此按15分钟取整，超过±14小时视为不可信
+func gpsZone(local, gps time.Time) (*time.Location, bool) {
+	diff := local.Sub(gps)
+	quarters := math.Round(diff.Minutes() / 15)
+	offset := time.Duration(quarters*15) * time.Minute
+	if offset < -14*time.Hour || offset > 14*time.Hour {
+		return nil, false
+	}
+	// 取整误差过大说明两个时间并非同一时刻
+	if (diff - offset).Abs() > 5*time.Minute {
+		return nil, false
+	}
+	return time.FixedZone("", int(offset.Seconds())), true
+}
+
+// exifRawTag 按标签ID读取 goexif 未映射的标签（在 IFD0 与 Exif 子IFD中查找）
+func exifRawTag(x *exif.Exif, id uint16) (*tiff.Tag, bool) {
+	var dirs []*tiff.Dir
+	if x.Tiff != nil && len(x.Tiff.Dirs) > 0 {
+		dirs = append(dirs, x.Tiff.Dirs[0])
+	}
+	if ptr, err := x.Get(exif.ExifIFDPointer); err == nil {
+		if offset, err := ptr.Int64(0); err == nil {
+			r := bytes.NewReader(x.Raw)
+			if _, err := r.Seek(offset, io.SeekStart); err == nil {
+				if dir, _, err := tiff.DecodeDir(r, x.Tiff.Order); err == nil {
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		for _, tag := range dir.Tags {
+			if tag.Id == id {
+				return tag, true
+			}
+		}
+	}
+	return nil, false
+}
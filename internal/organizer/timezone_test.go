@@ -0,0 +1,83 @@
+package organizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// testASCIITIFF 构造只包含 ASCII 标签的 TIFF（标签均位于 IFD0）
+func testASCIITIFF(tags [][2]string, ids []uint16) []byte {
+	dataOffset := 8 + 2 + 12*len(tags) + 4
+	buf := []byte("II*\x00")
+	buf = binary.LittleEndian.AppendUint32(buf, 8)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(tags)))
+
+	var data []byte
+	for i, tag := range tags {
+		value := append([]byte(tag[1]), 0)
+		buf = binary.LittleEndian.AppendUint16(buf, ids[i])
+		buf = binary.LittleEndian.AppendUint16(buf, 2)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(value)))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(dataOffset+len(data)))
+		data = append(data, value...)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+	return append(buf, data...)
+}
+
+func TestExifTagTimeOffset(t *testing.T) {
+	data := testASCIITIFF(
+		[][2]string{{"DateTimeOriginal", "2021:03:04 01:15:30"}, {"OffsetTimeOriginal", "+09:00"}},
+		[]uint16{0x9003, tagOffsetTimeOriginal},
+	)
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := exifTagTime(x, exif.DateTimeOriginal)
+	if !ok {
+		t.Fatalf("exifTagTime() found no date")
+	}
+	want := time.Date(2021, 3, 3, 16, 15, 30, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("exifTagTime() = %v, want %v", got, want)
+	}
+	if _, offset := got.Zone(); offset != 9*3600 {
+		t.Errorf("exifTagTime() offset = %d, want %d", offset, 9*3600)
+	}
+}
+
+func TestGPSZone(t *testing.T) {
+	local := time.Date(2021, 3, 4, 10, 15, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		gps      time.Time
+		expected int
+		ok       bool
+	}{
+		{"UTC+8", time.Date(2021, 3, 4, 2, 15, 28, 0, time.UTC), 8 * 3600, true},
+		{"UTC-5:30", time.Date(2021, 3, 4, 15, 45, 0, 0, time.UTC), -(5*3600 + 30*60), true},
+		{"Unrelated fix", time.Date(2021, 3, 4, 2, 37, 0, 0, time.UTC), 0, false},
+		{"Too far", time.Date(2021, 3, 2, 10, 15, 30, 0, time.UTC), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, ok := gpsZone(local, tt.gps)
+			if ok != tt.ok {
+				t.Fatalf("gpsZone() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok {
+				if _, offset := time.Date(2021, 1, 1, 0, 0, 0, 0, loc).Zone(); offset != tt.expected {
+					t.Errorf("gpsZone() offset = %d, want %d", offset, tt.expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package organizer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+// errReflinkUnsupported 当前平台或文件系统不支持写时复制克隆
+var errReflinkUnsupported = errors.New("不支持写时复制克隆")
+
+// transferFile 按指定的传输方式将源文件转移到目标路径，返回实际使用的方式
+// 记录操作日志时，已有目标会先移动到备份目录，失败（包括取消）时再恢复
+func (p *Processor) transferFile(ctx context.Context, file *FileInfo, mode config.TransferMode) (config.TransferMode, error) {
+	backup, err := p.backupTarget(file)
+	if err != nil {
+		return mode, err
+	}
+
+	used, err := p.transfer(ctx, file, mode)
+	if err != nil {
+		if backup != "" {
+			os.Rename(backup, file.TargetPath)
+		}
+		return used, err
+	}
+
+	p.journalTransfer(file, used, backup)
+	return used, nil
+}
+
+// transfer 执行传输，reflink 不可用时回退为普通复制
+func (p *Processor) transfer(ctx context.Context, file *FileInfo, mode config.TransferMode) (config.TransferMode, error) {
+	src, dst := file.Path, file.TargetPath
+	switch mode {
+	case config.TransferMove:
+		return mode, p.moveFile(ctx, file)
+
+	case config.TransferHardlink:
+		return mode, p.linkFile(dst, func() error { return os.Link(src, dst) })
+
+	case config.TransferSymlink:
+		// 使用绝对路径，避免链接随目标目录位置失效
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return mode, err
+		}
+		return mode, p.linkFile(dst, func() error { return os.Symlink(abs, dst) })
+
+	case config.TransferReflink:
+		err := p.linkFile(dst, func() error { return reflinkFile(src, dst) })
+		if errors.Is(err, errReflinkUnsupported) {
+			return config.TransferCopy, p.copyFile(ctx, file)
+		}
+		if err != nil {
+			return mode, err
+		}
+		// 克隆得到的是新文件，同样需要保留属性
+		return mode, p.applyTargetAttributes(file)
+
+	default:
+		return config.TransferCopy, p.copyFile(ctx, file)
+	}
+}
+
+// applyTargetAttributes 将权限、时间戳等属性应用到已生成的目标文件
+func (p *Processor) applyTargetAttributes(file *FileInfo) error {
+	srcInfo, err := os.Stat(file.Path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(file.TargetPath, p.targetMode(srcInfo)); err != nil {
+		return &TargetWriteError{Path: file.TargetPath, Err: err}
+	}
+	return p.applyAttributes(file, srcInfo, file.TargetPath)
+}
+
+// transferMode 返回配置的传输方式，未配置时为复制
+func (p *Processor) transferMode() config.TransferMode {
+	if p.config.TransferMode == "" {
+		return config.TransferCopy
+	}
+	return p.config.TransferMode
+}
+
+// moveFile 移动文件
+// 优先直接重命名；仅在跨设备时先复制并校验，再删除源文件
+func (p *Processor) moveFile(ctx context.Context, file *FileInfo) error {
+	src, dst := file.Path, file.TargetPath
+	if err := p.ensureDir(filepath.Dir(dst)); err != nil {
+		return &TargetWriteError{Path: dst, Err: err}
+	}
+	err := os.Rename(src, dst)
+	if err == nil {
+		// 重命名会保留所有属性，只需按需改写修改时间
+		if p.config.MtimeFromDate && !file.Date.IsZero() {
+			if err := chtimes(dst, time.Now(), file.Date); err != nil {
+				// 先把文件移回源位置，调用方才能安全地恢复备份；
+				// 无法移回时移动已经完成，按成功处理，避免备份覆盖刚移动的文件
+				if os.Rename(dst, src) != nil {
+					return nil
+				}
+				return &TargetWriteError{Path: dst, Err: err}
+			}
+		}
+		return nil
+	}
+	// 其他重命名错误（权限不足等）不能通过复制解决，直接返回
+	if !isCrossDevice(err) {
+		return err
+	}
+
+	if err := p.copyFile(ctx, file); err != nil {
+		return err
+	}
+
+	// 删除源文件前总是校验（开启 Verify 时复制阶段已经校验过）
+	if !p.config.Verify {
+		if err := verifyCopy(ctx, dst, file.Digest); err != nil {
+			os.Remove(dst)
+			return err
+		}
+	}
+
+	// 源文件无法删除时移除副本，保持移动前的状态，失败的记录不会留下未记录日志的目标
+	if err := os.Remove(src); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// linkFile 创建链接类目标（硬链接、符号链接、克隆）
+// 这些操作不会覆盖已有文件，因此先删除已存在的目标（仅在覆盖策略下才会出现）
+func (p *Processor) linkFile(dst string, create func() error) error {
+	if err := p.ensureDir(filepath.Dir(dst)); err != nil {
+		return &TargetWriteError{Path: dst, Err: err}
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		if err := os.Remove(dst); err != nil {
+			return &TargetWriteError{Path: dst, Err: err}
+		}
+	}
+	return create()
+}
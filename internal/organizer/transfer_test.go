@@ -0,0 +1,334 @@
+package organizer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
+
+func TestTransferFile(t *testing.T) {
+	tests := []struct {
+		mode       config.TransferMode
+		keepSource bool
+		check      func(t *testing.T, src, dst string, used config.TransferMode)
+	}{
+		{config.TransferCopy, true, nil},
+		{config.TransferMove, false, nil},
+		{config.TransferHardlink, true, func(t *testing.T, src, dst string, used config.TransferMode) {
+			srcInfo, _ := os.Stat(src)
+			dstInfo, _ := os.Stat(dst)
+			if !os.SameFile(srcInfo, dstInfo) {
+				t.Errorf("hardlink target is not the same file as the source")
+			}
+		}},
+		{config.TransferSymlink, true, func(t *testing.T, src, dst string, used config.TransferMode) {
+			info, err := os.Lstat(dst)
+			if err != nil || info.Mode()&os.ModeSymlink == 0 {
+				t.Errorf("symlink target is not a symbolic link")
+			}
+		}},
+		{config.TransferReflink, true, func(t *testing.T, src, dst string, used config.TransferMode) {
+			if used != config.TransferReflink && used != config.TransferCopy {
+				t.Errorf("reflink used mode = %s, want reflink or copy", used)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "source", "IMG_0001.jpg")
+			dst := filepath.Join(dir, "target", "2021", "IMG_0001.jpg")
+			if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(src, []byte("image data"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := config.NewDefaultConfig()
+			cfg.TransferMode = tt.mode
+			used, err := NewProcessor(cfg).transferFile(context.Background(), &FileInfo{Path: src, TargetPath: dst}, cfg.TransferMode)
+			if err != nil {
+				t.Fatalf("transferFile() error = %v", err)
+			}
+			if tt.check == nil && used != tt.mode {
+				t.Errorf("transferFile() used = %s, want %s", used, tt.mode)
+			}
+
+			data, err := os.ReadFile(dst)
+			if err != nil || string(data) != "image data" {
+				t.Errorf("target content = %q, %v", data, err)
+			}
+			if _, err := os.Stat(src); (err == nil) != tt.keepSource {
+				t.Errorf("source exists = %v, want %v", err == nil, tt.keepSource)
+			}
+			if tt.check != nil {
+				tt.check(t, src, dst, used)
+			}
+		})
+	}
+}
+
+func TestTransferFileReplacesLink(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	dst := filepath.Join(dir, "target", "IMG_0001.jpg")
+	os.WriteFile(src, []byte("new"), 0644)
+	os.MkdirAll(filepath.Dir(dst), 0755)
+	os.WriteFile(dst, []byte("old"), 0644)
+
+	cfg := config.NewDefaultConfig()
+	cfg.TransferMode = config.TransferHardlink
+	if _, err := NewProcessor(cfg).transferFile(context.Background(), &FileInfo{Path: src, TargetPath: dst}, cfg.TransferMode); err != nil {
+		t.Fatalf("transferFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "new" {
+		t.Errorf("target content = %q, want new", data)
+	}
+}
+
+func TestMoveFileChtimesFailure(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(t.TempDir())
+	src := filepath.Join(dir, "source", "IMG_0001.jpg")
+	dst := filepath.Join(dir, "target", "IMG_0001.jpg")
+	os.MkdirAll(filepath.Dir(src), 0755)
+	os.MkdirAll(filepath.Dir(dst), 0755)
+	os.WriteFile(src, []byte("new"), 0644)
+	os.WriteFile(dst, []byte("old"), 0644)
+
+	chtimesErr := errors.New("chtimes failed")
+	defer func(orig func(string, time.Time, time.Time) error) { chtimes = orig }(chtimes)
+	chtimes = func(string, time.Time, time.Time) error { return chtimesErr }
+
+	journal, err := NewJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = filepath.Join(dir, "target")
+	cfg.TransferMode = config.TransferMove
+	cfg.MtimeFromDate = true
+	processor := NewProcessor(cfg)
+	processor.SetJournal(journal)
+
+	file := &FileInfo{Path: src, TargetPath: dst, Type: FileTypePhoto, Date: time.Date(2021, 3, 4, 10, 15, 30, 0, time.UTC)}
+	if _, err := processor.transferFile(context.Background(), file, cfg.TransferMode); !errors.Is(err, chtimesErr) {
+		t.Fatalf("transferFile() error = %v, want %v", err, chtimesErr)
+	}
+	// 移动被撤销，源文件和原有目标都保持不变
+	if data, err := os.ReadFile(src); err != nil || string(data) != "new" {
+		t.Errorf("source content = %q, %v, want new", data, err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "old" {
+		t.Errorf("target content = %q, %v, want old", data, err)
+	}
+}
+
+func TestIsCrossDevice(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&os.LinkError{Op: "rename", Old: "a", New: "b", Err: syscall.EXDEV}, runtime.GOOS != "windows"},
+		{&os.LinkError{Op: "rename", Old: "a", New: "b", Err: syscall.ENOENT}, false},
+		{&os.LinkError{Op: "rename", Old: "a", New: "b", Err: os.ErrPermission}, false},
+	}
+	for _, tt := range tests {
+		if got := isCrossDevice(tt.err); got != tt.want {
+			t.Errorf("isCrossDevice(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMoveFileRenameError(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "target", "IMG_0001.jpg")
+
+	cfg := config.NewDefaultConfig()
+	cfg.TransferMode = config.TransferMove
+	// 源文件不存在时不能回退为复制
+	file := &FileInfo{Path: filepath.Join(dir, "missing.jpg"), TargetPath: dst}
+	if _, err := NewProcessor(cfg).transferFile(context.Background(), file, cfg.TransferMode); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("transferFile() error = %v, want not exist", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dst))
+	if len(entries) != 0 {
+		t.Errorf("target directory has %d entries, want none", len(entries))
+	}
+}
+
+func TestCopyFileLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	dst := filepath.Join(dir, "target", "IMG_0001.jpg")
+	os.WriteFile(src, []byte("image data"), 0644)
+
+	if err := NewProcessor(config.NewDefaultConfig()).copyFile(context.Background(), &FileInfo{Path: src, TargetPath: dst}); err != nil {
+		t.Fatalf("copyFile() error = %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dst))
+	if len(entries) != 1 || entries[0].Name() != "IMG_0001.jpg" {
+		t.Errorf("target dir entries = %v, want only IMG_0001.jpg", entries)
+	}
+}
+
+func TestCopyFileCancelled(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	dst := filepath.Join(dir, "target", "IMG_0001.jpg")
+	os.WriteFile(src, []byte("image data"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := NewProcessor(config.NewDefaultConfig()).copyFile(ctx, &FileInfo{Path: src, TargetPath: dst})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("copyFile() error = %v, want context.Canceled", err)
+	}
+	// 未完成的临时文件已删除，目标不存在
+	if entries, _ := os.ReadDir(filepath.Dir(dst)); len(entries) != 0 {
+		t.Errorf("target dir entries = %v, want none", entries)
+	}
+}
+
+func TestCopyFileTargetWriteError(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	os.WriteFile(src, []byte("image data"), 0644)
+	// 目标目录的父路径是普通文件，无法创建目录
+	blocker := filepath.Join(dir, "blocker")
+	os.WriteFile(blocker, nil, 0644)
+
+	err := NewProcessor(config.NewDefaultConfig()).copyFile(context.Background(), &FileInfo{Path: src, TargetPath: filepath.Join(blocker, "2021", "IMG_0001.jpg")})
+	var writeErr *TargetWriteError
+	if !errors.As(err, &writeErr) {
+		t.Errorf("copyFile() error = %v, want TargetWriteError", err)
+	}
+
+	// 源文件不存在不属于写入目标失败
+	err = NewProcessor(config.NewDefaultConfig()).copyFile(context.Background(), &FileInfo{Path: filepath.Join(dir, "missing.jpg"), TargetPath: filepath.Join(dir, "out.jpg")})
+	if err == nil || errors.As(err, &writeErr) {
+		t.Errorf("copyFile() error = %v, want plain read error", err)
+	}
+}
+
+func TestCopyFileAttributes(t *testing.T) {
+	date := time.Date(2021, 3, 14, 9, 26, 53, 0, time.UTC)
+	atime := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	mtime := time.Date(2022, 6, 7, 8, 9, 10, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+		wantMode  os.FileMode
+		wantMtime time.Time
+	}{
+		{"defaults", func(cfg *config.Config) {}, 0644, time.Time{}},
+		{"preserve", func(cfg *config.Config) {
+			cfg.PreserveTimes = true
+			cfg.PreserveMode = true
+		}, 0600, mtime},
+		{"mtime from date", func(cfg *config.Config) {
+			cfg.PreserveTimes = true
+			cfg.MtimeFromDate = true
+		}, 0644, date},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "IMG_0001.jpg")
+			dst := filepath.Join(dir, "target", "IMG_0001.jpg")
+			os.WriteFile(src, []byte("image data"), 0600)
+			os.Chtimes(src, atime, mtime)
+
+			cfg := config.NewDefaultConfig()
+			tt.configure(cfg)
+			if err := NewProcessor(cfg).copyFile(context.Background(), &FileInfo{Path: src, TargetPath: dst, Date: date}); err != nil {
+				t.Fatalf("copyFile() error = %v", err)
+			}
+
+			info, err := os.Stat(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if runtime.GOOS != "windows" && info.Mode().Perm() != tt.wantMode {
+				t.Errorf("mode = %v, want %v", info.Mode().Perm(), tt.wantMode)
+			}
+			if !tt.wantMtime.IsZero() && !info.ModTime().Equal(tt.wantMtime) {
+				t.Errorf("mtime = %v, want %v", info.ModTime(), tt.wantMtime)
+			}
+			if tt.wantMtime.IsZero() && info.ModTime().Equal(mtime) {
+				t.Errorf("mtime preserved without PreserveTimes")
+			}
+		})
+	}
+}
+
+func TestCleanupTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "2021", "03", tempFilePrefix+"123"+tempFileSuffix)
+	kept := filepath.Join(dir, "2021", "03", "IMG_0001.jpg")
+	os.MkdirAll(filepath.Dir(stale), 0755)
+	os.WriteFile(stale, []byte("partial"), 0644)
+	os.WriteFile(kept, []byte("image data"), 0644)
+
+	cfg := config.NewDefaultConfig()
+	cfg.TargetDir = dir
+	cfg.VideoTargetDir = filepath.Join(dir, "missing")
+	removed, err := NewProcessor(cfg).CleanupTempFiles()
+	if err != nil || removed != 1 {
+		t.Fatalf("CleanupTempFiles() = %d, %v, want 1, nil", removed, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale temp file still exists")
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("regular file removed: %v", err)
+	}
+}
+
+func TestCopyFileVerify(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "IMG_0001.jpg")
+	dst := filepath.Join(dir, "target", "IMG_0001.jpg")
+	os.WriteFile(src, []byte("image data"), 0644)
+	want, _ := CalculateDigest(context.Background(), src, hashers["sha256"])
+
+	cfg := config.NewDefaultConfig()
+	cfg.Verify = true
+	cfg.HashAlgorithm = config.HashSHA256
+	file := &FileInfo{Path: src, TargetPath: dst}
+	if err := NewProcessor(cfg).copyFile(context.Background(), file); err != nil {
+		t.Fatalf("copyFile() error = %v", err)
+	}
+	if file.Digest != want {
+		t.Errorf("FileInfo.Digest = %q, want %q", file.Digest, want)
+	}
+
+	// 校验失败时不会替换目标，临时文件也会被清理
+	tmp, _ := NewProcessor(cfg).createTempFile(dst)
+	tmp.WriteString("corrupted")
+	err := commitTempFile(tmp, dst, func(path string) error { return verifyCopy(context.Background(), path, want) })
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("commitTempFile() error = %v, want VerifyError", err)
+	}
+	os.Remove(tmp.Name())
+	if data, _ := os.ReadFile(dst); string(data) != "image data" {
+		t.Errorf("target content = %q, want original copy", data)
+	}
+	if msg := transferErrorMessage(config.TransferCopy, err); msg == transferErrorMessage(config.TransferCopy, errors.New(err.Error())) {
+		t.Errorf("verification failure reported as generic transfer error: %s", msg)
+	}
+}
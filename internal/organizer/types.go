@@ -1,6 +1,10 @@
 package organizer
 
-import "time"
+import (
+	"time"
+
+	"github.com/chiyiangel/media-organizer-v2/internal/config"
+)
 
 // FileType 文件类型
 type FileType string
@@ -11,15 +15,51 @@ const (
 	FileTypeOther FileType = "other" // 其他
 )
 
+// DateSource 日期来源
+type DateSource string
+
+const (
+	DateSourceEXIF      DateSource = "exif"      // EXIF
+	DateSourceQuickTime DateSource = "quicktime" // MP4/MOV 容器元数据
+	DateSourceFilename  DateSource = "filename"  // 文件名
+	DateSourceSidecar   DateSource = "sidecar"   // XMP 附属文件
+	DateSourceMtime     DateSource = "mtime"     // 文件修改时间
+)
+
+// DateSources 日期来源（按可信度排序，用于汇总展示）
+var DateSources = []DateSource{
+	DateSourceEXIF,
+	DateSourceQuickTime,
+	DateSourceFilename,
+	DateSourceSidecar,
+	DateSourceMtime,
+}
+
 // FileInfo 文件信息
 type FileInfo struct {
-	Path       string    // 文件路径
-	Name       string    // 文件名
-	Type       FileType  // 文件类型
-	Size       int64     // 文件大小
-	Date       time.Time // 日期（来自EXIF或创建时间）
-	MD5        string    // MD5哈希（按需计算）
-	TargetPath string    // 目标路径
+	Path        string     // 文件路径
+	Name        string     // 文件名
+	Subfolder   string     // 相对源目录的子目录（使用 "/" 分隔，根目录为空）
+	Type        FileType   // 文件类型
+	Size        int64      // 文件大小
+	Date        time.Time  // 日期（来自EXIF或创建时间）
+	DateSource  DateSource // 日期来源
+	CameraMake  string     // 相机厂商（按需读取）
+	CameraModel string     // 相机型号（按需读取）
+	Digest      Digest     // 内容摘要及其算法（按需计算）
+	TargetPath  string     // 目标路径
+
+	SourceDuplicateOf string // 源目录中内容相同、代替本文件导入的文件（见 DedupeSource）
+	SimilarTo         string // 目标库中相似的照片（感知哈希模式，两张都会保留）
+	Replaces          string // 已被本文件替换（移到备份目录）的较低分辨率相似照片（保留较高分辨率版本时）
+
+	seqKey  string    // 序号计数键，断点续传时用于恢复序号
+	seq     int       // 分配的序号
+	tiers   HashTiers // 分配完成时本次运行的分级比较计数，断点续传时用于恢复
+	partial Digest    // 首尾部分摘要（按需计算，见 partialHash）
+
+	perceptual *perceptualInfo // 感知哈希和分辨率（感知哈希模式，见 PreparePerceptual）
+	replaces   string          // 传输成功后要替换的较低分辨率相似照片（见 checkSimilar）
 }
 
 // ProcessResult 处理结果
@@ -31,11 +71,25 @@ const (
 	ResultFailed  ProcessResult = "failed"  // 失败
 )
 
+// PlanAction 对目标路径执行的操作
+type PlanAction string
+
+const (
+	ActionTransfer  PlanAction = "transfer"  // 传输到新路径
+	ActionOverwrite PlanAction = "overwrite" // 覆盖已有文件
+	ActionRename    PlanAction = "rename"    // 目标已存在，改名后传输
+	ActionSkip      PlanAction = "skip"      // 跳过
+)
+
 // ProcessRecord 处理记录
 type ProcessRecord struct {
-	File    *FileInfo     // 文件信息
-	Result  ProcessResult // 处理结果
-	Message string        // 消息（错误信息等）
+	File     *FileInfo           // 文件信息
+	Result   ProcessResult       // 处理结果
+	Message  string              // 消息（错误信息等）
+	Action   PlanAction          // 计划的操作
+	Transfer config.TransferMode // 实际使用的传输方式（演练模式下为配置的方式）
+
+	DuplicateOf string // 内容相同的已有文件位置（目标库内容索引模式下可能不在目标路径）
 }
 
 // Statistics 统计信息
@@ -50,6 +104,39 @@ type Statistics struct {
 	StartTime      time.Time     // 开始时间
 	EndTime        time.Time     // 结束时间
 	Duration       time.Duration // 耗时
+	Cancelled      bool          // 是否被取消（未处理 TotalFiles - ProcessedFiles 个文件）
+	HashTiers      HashTiers     // 内容比较各级得出结论的次数
+
+	DateSourceCounts map[DateSource]int // 各日期来源的文件数
+	WrittenCounts    map[FileType]int   // 各文件类型成功整理到目标目录的文件数（不含跳过和失败）
+}
+
+// Merge 合并之前（例如中断前）的统计
+func (s *Statistics) Merge(prev *Statistics) {
+	s.TotalFiles += prev.TotalFiles
+	s.ScannedFiles += prev.ScannedFiles
+	s.ProcessedFiles += prev.ProcessedFiles
+	s.PhotoCount += prev.PhotoCount
+	s.VideoCount += prev.VideoCount
+	s.SkippedCount += prev.SkippedCount
+	s.FailedCount += prev.FailedCount
+	s.Duration += prev.Duration
+	s.HashTiers.Add(prev.HashTiers)
+	if !prev.StartTime.IsZero() && (s.StartTime.IsZero() || prev.StartTime.Before(s.StartTime)) {
+		s.StartTime = prev.StartTime
+	}
+	for source, count := range prev.DateSourceCounts {
+		if s.DateSourceCounts == nil {
+			s.DateSourceCounts = make(map[DateSource]int)
+		}
+		s.DateSourceCounts[source] += count
+	}
+	for fileType, count := range prev.WrittenCounts {
+		if s.WrittenCounts == nil {
+			s.WrittenCounts = make(map[FileType]int)
+		}
+		s.WrittenCounts[fileType] += count
+	}
 }
 
 // GetSpeed 计算处理速度（文件/秒）
@@ -59,3 +146,52 @@ func (s *Statistics) GetSpeed() float64 {
 	}
 	return float64(s.ProcessedFiles) / s.Duration.Seconds()
 }
+
+// AddDateSource 记录一个文件的日期来源
+func (s *Statistics) AddDateSource(source DateSource) {
+	if source == "" {
+		return
+	}
+	if s.DateSourceCounts == nil {
+		s.DateSourceCounts = make(map[DateSource]int)
+	}
+	s.DateSourceCounts[source]++
+}
+
+// AddResult 记录一个文件的处理结果，只有成功的文件计入目标目录的文件数
+func (s *Statistics) AddResult(record *ProcessRecord) {
+	switch record.Result {
+	case ResultSuccess:
+		if s.WrittenCounts == nil {
+			s.WrittenCounts = make(map[FileType]int)
+		}
+		s.WrittenCounts[record.File.Type]++
+	case ResultSkipped:
+		s.SkippedCount++
+	case ResultFailed:
+		s.FailedCount++
+	}
+}
+
+// DateSourcePercent 计算某个日期来源占已确定日期文件的百分比
+func (s *Statistics) DateSourcePercent(source DateSource) float64 {
+	total := 0
+	for _, count := range s.DateSourceCounts {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(s.DateSourceCounts[source]) / float64(total) * 100
+}
+
+// similarPhoto 返回目标库中与照片相似的照片（感知哈希模式），没有时返回空字符串
+func (f *FileInfo) similarPhoto() string {
+	switch {
+	case f.Replaces != "":
+		return f.Replaces
+	case f.SimilarTo != "":
+		return f.SimilarTo
+	}
+	return f.replaces
+}
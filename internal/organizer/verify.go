@@ -0,0 +1,36 @@
+package organizer
+
+import (
+	"context"
+	"fmt"
+)
+
+// VerifyError 复制后的目标内容与源数据不一致
+type VerifyError struct {
+	Path string
+	Want Digest
+	Got  Digest
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%s: 期望 %s %s，实际 %s", e.Path, e.Want.Algorithm(), e.Want.Hex(), e.Got.Hex())
+}
+
+// verifyCopy 重新读取已写入的文件，使用相同算法与复制时计算的摘要比较
+// 读取前在 Linux 上丢弃文件的页缓存，使校验读到磁盘上的数据；其他平台上可能读到刚写入的缓存，
+// 只能发现复制过程中的错误，发现不了写入磁盘时的损坏
+func verifyCopy(ctx context.Context, path string, want Digest) error {
+	hasher, err := want.hasher()
+	if err != nil {
+		return err
+	}
+	dropPageCache(path)
+	got, err := CalculateDigest(ctx, path, hasher)
+	if err != nil {
+		return &TargetWriteError{Path: path, Err: err}
+	}
+	if got != want {
+		return &VerifyError{Path: path, Want: want, Got: got}
+	}
+	return nil
+}
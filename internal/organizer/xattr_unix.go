@@ -0,0 +1,48 @@
+//go:build linux || darwin
+
+package organizer
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyXattrs 复制扩展属性（例如 Finder 标签）
+// 文件系统不支持或无权限写入的属性（如 security.*）会被跳过
+func copyXattrs(src, dst string) error {
+	size, err := unix.Listxattr(src, nil)
+	if err != nil || size == 0 {
+		if errors.Is(err, unix.ENOTSUP) {
+			return nil
+		}
+		return err
+	}
+	names := make([]byte, size)
+	if size, err = unix.Listxattr(src, names); err != nil {
+		return err
+	}
+
+	for _, name := range bytes.Split(names[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		attr := string(name)
+		n, err := unix.Getxattr(src, attr, nil)
+		if err != nil {
+			return err
+		}
+		value := make([]byte, n)
+		if n, err = unix.Getxattr(src, attr, value); err != nil {
+			return err
+		}
+		if err := unix.Setxattr(dst, attr, value[:n], 0); err != nil {
+			if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EPERM) || errors.Is(err, unix.EACCES) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
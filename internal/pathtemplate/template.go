@@ -0,0 +1,414 @@
+// Package pathtemplate 实现目标路径模板
+//
+// 模板由普通文本和 {token} 或 {token:参数} 形式的占位符组成，例如:
+//
+//	{year}/{month:02}-{monthname}/{date:2006-01-02}_{camera}/{name}{ext}
+//
+// 模板结果使用 "/" 作为分隔符，由调用方转换为本地路径。
+package pathtemplate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLayout 默认模板，对应 YYYY/MM/MM-DD/文件名
+const DefaultLayout = "{year}/{month}/{month}-{day}/{name}{ext}"
+
+// unknownValue 缺失的相机信息等使用的占位值
+const unknownValue = "Unknown"
+
+// Values 模板取值
+type Values struct {
+	Date      time.Time // 拍摄时间（已换算到归档时区），用于时间占位符和文件名中的 {date}
+	Bucket    time.Time // 归档日（按一天的起始小时调整），用于 {year}、{month}、{day}、{monthname} 和目录中的 {date}，为零时使用 Date
+	Name      string    // 原文件名（不含扩展名）
+	Ext       string    // 扩展名（含点）
+	Type      string    // 文件类型（photo/video）
+	Make      string    // 相机厂商
+	Model     string    // 相机型号
+	Subfolder string    // 相对源目录的子目录（使用 "/" 分隔）
+	Seq       int       // 序号，用于区分同一时间的多个文件
+
+	// Hash 返回内容哈希（十六进制），仅在模板使用 {hash} 时调用
+	Hash func() (string, error)
+}
+
+// tokenFunc 渲染单个占位符
+type tokenFunc func(v *Values, arg string) (string, error)
+
+// tokenSpec 占位符定义
+type tokenSpec struct {
+	render    tokenFunc
+	renderDir tokenFunc // 占位符位于目录片段时使用，为空时使用 render
+	validate  func(arg string) error
+}
+
+// tokens 可用的占位符
+var tokens = map[string]tokenSpec{
+	"year":   {render: numberToken((*Values).bucket, func(t time.Time) int { return t.Year() }, 4), validate: validateWidth},
+	"month":  {render: numberToken((*Values).bucket, func(t time.Time) int { return int(t.Month()) }, 2), validate: validateWidth},
+	"day":    {render: numberToken((*Values).bucket, func(t time.Time) int { return t.Day() }, 2), validate: validateWidth},
+	"hour":   {render: numberToken((*Values).date, func(t time.Time) int { return t.Hour() }, 2), validate: validateWidth},
+	"minute": {render: numberToken((*Values).date, func(t time.Time) int { return t.Minute() }, 2), validate: validateWidth},
+	"second": {render: numberToken((*Values).date, func(t time.Time) int { return t.Second() }, 2), validate: validateWidth},
+	"monthname": {render: func(v *Values, arg string) (string, error) {
+		name := v.bucket().Month().String()
+		if arg == "short" {
+			name = name[:3]
+		}
+		return name, nil
+	}, validate: validateChoice("", "short")},
+	"date": {render: func(v *Values, arg string) (string, error) {
+		// 格式本身包含目录时（例如 {date:2006/01/02_150405}），目录部分按归档日渲染
+		if i := strings.LastIndexAny(arg, "/\\"); i >= 0 {
+			return sanitizePath(v.bucket().Format(arg[:i+1]) + v.Date.Format(arg[i+1:])), nil
+		}
+		return sanitizePath(v.Date.Format(arg)), nil
+	}, renderDir: func(v *Values, arg string) (string, error) {
+		return sanitizePath(v.bucket().Format(arg)), nil
+	}, validate: func(arg string) error {
+		if arg == "" {
+			return fmt.Errorf("{date} 需要日期格式参数，例如 {date:2006-01-02}")
+		}
+		return nil
+	}},
+	"name": {render: func(v *Values, arg string) (string, error) {
+		return sanitize(v.Name), nil
+	}},
+	"ext": {render: func(v *Values, arg string) (string, error) {
+		switch arg {
+		case "lower":
+			return strings.ToLower(v.Ext), nil
+		case "upper":
+			return strings.ToUpper(v.Ext), nil
+		}
+		return v.Ext, nil
+	}, validate: validateChoice("", "lower", "upper")},
+	"type": {render: func(v *Values, arg string) (string, error) {
+		return v.Type, nil
+	}},
+	"make": {render: func(v *Values, arg string) (string, error) {
+		return orUnknown(sanitize(v.Make)), nil
+	}},
+	"model": {render: func(v *Values, arg string) (string, error) {
+		return orUnknown(sanitize(v.Model)), nil
+	}},
+	"camera": {render: func(v *Values, arg string) (string, error) {
+		return orUnknown(sanitize(cameraName(v.Make, v.Model))), nil
+	}},
+	"subfolder": {render: func(v *Values, arg string) (string, error) {
+		parts := strings.Split(v.Subfolder, "/")
+		for i, part := range parts {
+			parts[i] = sanitize(part)
+		}
+		return strings.Join(parts, "/"), nil
+	}},
+	"seq": {render: func(v *Values, arg string) (string, error) {
+		width := 1
+		if arg != "" {
+			width, _ = strconv.Atoi(arg)
+		}
+		return fmt.Sprintf("%0*d", width, v.Seq), nil
+	}, validate: validateWidth},
+	"hash": {render: func(v *Values, arg string) (string, error) {
+		if v.Hash == nil {
+			return "", fmt.Errorf("无法计算哈希")
+		}
+		hash, err := v.Hash()
+		if err != nil {
+			return "", err
+		}
+		length := 8
+		if arg != "" {
+			length, _ = strconv.Atoi(arg)
+		}
+		if length < len(hash) {
+			hash = hash[:length]
+		}
+		return hash, nil
+	}, validate: func(arg string) error {
+		if arg == "" {
+			return nil
+		}
+		if n, err := strconv.Atoi(arg); err != nil || n < 1 || n > 64 {
+			return fmt.Errorf("{hash} 的长度参数无效: %s", arg)
+		}
+		return nil
+	}},
+}
+
+// segment 模板片段（普通文本或占位符）
+type segment struct {
+	literal string
+	token   string
+	arg     string
+	dir     bool // 占位符后面还有路径分隔符，即位于目录片段
+}
+
+// Template 已解析的模板
+type Template struct {
+	layout   string
+	segments []segment
+}
+
+// Parse 解析并校验模板
+func Parse(layout string) (*Template, error) {
+	if strings.TrimSpace(layout) == "" {
+		return nil, fmt.Errorf("模板不能为空")
+	}
+
+	t := &Template{layout: layout}
+	rest := layout
+	for rest != "" {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			t.segments = append(t.segments, segment{literal: rest})
+			break
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("模板中存在未配对的 '}': %s", layout)
+		}
+		if open > 0 {
+			t.segments = append(t.segments, segment{literal: rest[:open]})
+		}
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("模板中存在未闭合的 '{': %s", layout)
+		}
+		body := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+
+		name, arg, _ := strings.Cut(body, ":")
+		spec, ok := tokens[name]
+		if !ok {
+			return nil, fmt.Errorf("未知的模板占位符: {%s}", name)
+		}
+		if spec.validate != nil {
+			if err := spec.validate(arg); err != nil {
+				return nil, err
+			}
+		} else if arg != "" {
+			return nil, fmt.Errorf("占位符 {%s} 不接受参数", name)
+		}
+		t.segments = append(t.segments, segment{token: name, arg: arg})
+	}
+
+	// 从后向前标记位于目录片段的占位符
+	separatorAfter := false
+	for i := len(t.segments) - 1; i >= 0; i-- {
+		seg := &t.segments[i]
+		if seg.token != "" {
+			seg.dir = separatorAfter
+		}
+		if strings.ContainsAny(seg.literal, "/\\") || (seg.token == "date" && strings.ContainsAny(seg.arg, "/\\")) {
+			separatorAfter = true
+		}
+	}
+
+	if strings.HasPrefix(layout, "/") || strings.HasPrefix(layout, "\\") {
+		return nil, fmt.Errorf("模板必须是相对路径: %s", layout)
+	}
+	if last := t.segments[len(t.segments)-1]; last.token == "" && strings.HasSuffix(last.literal, "/") {
+		return nil, fmt.Errorf("模板必须以文件名结尾: %s", layout)
+	}
+	for _, part := range strings.FieldsFunc(layout, isSeparator) {
+		if part == "." || part == ".." {
+			return nil, fmt.Errorf("模板不能包含 '%s': %s", part, layout)
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(layout, "\\", "/"), "//") {
+		return nil, fmt.Errorf("模板不能包含空的路径片段: %s", layout)
+	}
+
+	return t, nil
+}
+
+// ParseName 解析文件名模板（用于重命名），结果不能包含目录
+func ParseName(layout string) (*Template, error) {
+	t, err := Parse(layout)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range t.segments {
+		switch {
+		case seg.token == "" && strings.ContainsAny(seg.literal, "/\\"):
+			return nil, fmt.Errorf("文件名模板不能包含目录: %s", layout)
+		case seg.token == "subfolder":
+			return nil, fmt.Errorf("文件名模板不能使用 {subfolder}: %s", layout)
+		case seg.token == "date" && strings.ContainsAny(seg.arg, "/\\"):
+			return nil, fmt.Errorf("文件名模板的日期格式不能包含目录: %s", layout)
+		}
+	}
+	return t, nil
+}
+
+// MustParse 解析模板，失败时 panic
+func MustParse(layout string) *Template {
+	t, err := Parse(layout)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String 返回原始模板
+func (t *Template) String() string {
+	return t.layout
+}
+
+// Uses 判断模板是否使用了指定占位符
+func (t *Template) Uses(names ...string) bool {
+	for _, seg := range t.segments {
+		for _, name := range names {
+			if seg.token == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Execute 渲染模板，返回以 "/" 分隔的相对路径
+// 渲染结果中的每个路径片段都不能为空、"." 或 ".."（例如 {date:..}），
+// 只有 {subfolder} 为空时省略它后面的分隔符
+func (t *Template) Execute(v *Values) (string, error) {
+	var b strings.Builder
+	skipSeparator := false
+	for _, seg := range t.segments {
+		if seg.token == "" {
+			literal := seg.literal
+			if skipSeparator {
+				literal = strings.TrimLeftFunc(literal, isSeparator)
+			}
+			b.WriteString(literal)
+			skipSeparator = false
+			continue
+		}
+		spec := tokens[seg.token]
+		render := spec.render
+		if seg.dir && spec.renderDir != nil {
+			render = spec.renderDir
+		}
+		value, err := render(v, seg.arg)
+		if err != nil {
+			return "", err
+		}
+		if seg.token == "subfolder" && value == "" {
+			skipSeparator = true
+			continue
+		}
+		b.WriteString(value)
+		skipSeparator = false
+	}
+
+	path := strings.ReplaceAll(b.String(), "\\", "/")
+	for _, part := range strings.Split(path, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("模板生成的路径包含无效的片段 %q: %s", part, path)
+		}
+	}
+	return path, nil
+}
+
+// date 返回拍摄时间
+func (v *Values) date() time.Time {
+	return v.Date
+}
+
+// bucket 返回归档日，未设置时使用拍摄时间
+func (v *Values) bucket() time.Time {
+	if v.Bucket.IsZero() {
+		return v.Date
+	}
+	return v.Bucket
+}
+
+// numberToken 数字占位符，参数为补零宽度（例如 02）
+// when 选择使用拍摄时间还是归档日
+func numberToken(when func(*Values) time.Time, get func(time.Time) int, defaultWidth int) tokenFunc {
+	return func(v *Values, arg string) (string, error) {
+		width := defaultWidth
+		if arg != "" {
+			width, _ = strconv.Atoi(arg)
+		}
+		return fmt.Sprintf("%0*d", width, get(when(v))), nil
+	}
+}
+
+// validateWidth 校验补零宽度参数
+func validateWidth(arg string) error {
+	if arg == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(arg); err != nil || n < 0 || n > 9 {
+		return fmt.Errorf("无效的宽度参数: %s", arg)
+	}
+	return nil
+}
+
+// validateChoice 校验参数是否为允许的值之一
+func validateChoice(choices ...string) func(arg string) error {
+	return func(arg string) error {
+		for _, choice := range choices {
+			if arg == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("无效的参数: %s (有效值: %s)", arg, strings.Join(choices[1:], ", "))
+	}
+}
+
+// cameraName 组合厂商与型号，型号已包含厂商名时不重复
+func cameraName(make, model string) string {
+	make = strings.TrimSpace(make)
+	model = strings.TrimSpace(model)
+	switch {
+	case model == "":
+		return make
+	case make == "" || strings.HasPrefix(strings.ToLower(model), strings.ToLower(make)):
+		return model
+	default:
+		return make + " " + model
+	}
+}
+
+// orUnknown 空值替换为占位值
+func orUnknown(s string) string {
+	if s == "" {
+		return unknownValue
+	}
+	return s
+}
+
+// sanitize 替换路径中不允许出现的字符
+func sanitize(s string) string {
+	s = strings.TrimRight(strings.TrimSpace(s), "\x00")
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 0x20 {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// sanitizePath 逐个路径片段替换不允许的字符，保留分隔符（例如 {date:2006/01} 生成的目录）
+func sanitizePath(s string) string {
+	parts := strings.Split(strings.ReplaceAll(s, "\\", "/"), "/")
+	for i, part := range parts {
+		parts[i] = sanitize(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// isSeparator 判断是否为路径分隔符
+func isSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
@@ -0,0 +1,186 @@
+package pathtemplate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  string
+		wantErr bool
+	}{
+		{"Default", DefaultLayout, false},
+		{"All tokens", "{year}/{month:02}-{monthname:short}/{date:2006-01-02}_{camera}/{make}_{model}/{type}/{subfolder}/{hash:8}_{name}_{seq:03}{ext:lower}", false},
+		{"Empty", "", true},
+		{"Unknown token", "{year}/{album}/{name}{ext}", true},
+		{"Unclosed brace", "{year/{name}{ext}", true},
+		{"Stray closing brace", "year}/{name}{ext}", true},
+		{"Bad width", "{month:xx}/{name}{ext}", true},
+		{"Date without layout", "{date}/{name}{ext}", true},
+		{"Bad hash length", "{hash:0}{ext}", true},
+		{"Unexpected argument", "{name:upper}{ext}", true},
+		{"Absolute", "/{year}/{name}{ext}", true},
+		{"Parent directory", "{year}/../{name}{ext}", true},
+		{"Current directory", "{year}/./{name}{ext}", true},
+		{"Empty segment", "{year}//{name}{ext}", true},
+		{"No file name", "{year}/{month}/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.layout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.layout, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  string
+		wantErr bool
+	}{
+		{"Timestamp", "{date:20060102_150405}_{seq:03}{ext}", false},
+		{"Camera and name", "{camera}_{name}{ext}", false},
+		{"Directory", "{year}/{name}{ext}", true},
+		{"Subfolder", "{subfolder}{ext}", true},
+		{"Date with directory", "{date:2006/01}{ext}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseName(tt.layout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseName(%q) error = %v, wantErr %v", tt.layout, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	values := &Values{
+		Date:  time.Date(2021, 3, 4, 9, 5, 7, 0, time.UTC),
+		Name:  "IMG_0001",
+		Ext:   ".JPG",
+		Type:  "photo",
+		Make:  "NIKON CORPORATION",
+		Model: "NIKON Z 6",
+		Hash:  func() (string, error) { return "abcdef0123456789", nil },
+		Seq:   2,
+	}
+
+	tests := []struct {
+		name     string
+		layout   string
+		expected string
+	}{
+		{"Default", DefaultLayout, "2021/03/03-04/IMG_0001.JPG"},
+		{"Width", "{year:02}/{month:1}/{hour}{minute}{second}{ext}", "2021/3/090507.JPG"},
+		{"Month name", "{monthname}/{monthname:short}{ext}", "March/Mar.JPG"},
+		{"Date layout", "{date:2006/01}/{name}{ext:lower}", "2021/03/IMG_0001.jpg"},
+		{"Camera", "{camera}/{make}/{model}{ext}", "NIKON CORPORATION NIKON Z 6/NIKON CORPORATION/NIKON Z 6.JPG"},
+		{"Hash", "{hash}_{hash:4}{ext}", "abcdef01_abcd.JPG"},
+		{"Sequence", "{date:20060102_150405}_{seq:03}_{seq}{ext}", "20210304_090507_002_2.JPG"},
+		{"Empty subfolder", "{subfolder}/{name}{ext}", "IMG_0001.JPG"},
+		{"Empty nested subfolder", "{year}/{subfolder}/{name}{ext}", "2021/IMG_0001.JPG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := MustParse(tt.layout).Execute(values)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Execute(%q) = %s, want %s", tt.layout, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteInvalidSegment(t *testing.T) {
+	values := &Values{Date: time.Date(2021, 3, 4, 9, 5, 7, 0, time.UTC), Name: "IMG_0001", Ext: ".jpg"}
+
+	tests := []struct {
+		name   string
+		layout string
+	}{
+		{"Parent directory", "{date:..}/{name}{ext}"},
+		{"Current directory", "{year}/{date:.}/{name}{ext}"},
+		{"Leading separator", "{date:/2006}/{name}{ext}"},
+		{"Empty file name", "{year}/{subfolder}"},
+		{"Empty segment", "{date:2006/}/{name}{ext}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result, err := MustParse(tt.layout).Execute(values); err == nil {
+				t.Errorf("Execute(%q) = %s, want error", tt.layout, result)
+			}
+		})
+	}
+}
+
+func TestExecuteBucket(t *testing.T) {
+	// 凌晨的照片归入前一天（包括目录中的 {date}），但时间占位符和文件名中的 {date} 仍使用拍摄时间
+	values := &Values{
+		Date:   time.Date(2021, 3, 4, 2, 15, 30, 0, time.UTC),
+		Bucket: time.Date(2021, 3, 3, 22, 15, 30, 0, time.UTC),
+		Ext:    ".jpg",
+	}
+
+	tests := []struct {
+		name     string
+		layout   string
+		expected string
+	}{
+		{"Day tokens", "{year}/{month}/{monthname}-{day}{ext}", "2021/03/March-03.jpg"},
+		{"Time tokens", "{hour}{minute}{second}{ext}", "021530.jpg"},
+		{"Date layout", "{date:20060102_150405}{ext}", "20210304_021530.jpg"},
+		{"Date directory", "{year}/{month:02}-{monthname}/{date:2006-01-02}/{date:150405}{ext}", "2021/03-March/2021-03-03/021530.jpg"},
+		{"Date layout with directory", "{date:2006/01/02_150405}{ext}", "2021/03/04_021530.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := MustParse(tt.layout).Execute(values)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Execute(%q) = %s, want %s", tt.layout, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExecuteSanitize(t *testing.T) {
+	values := &Values{Name: "a:b", Ext: ".jpg", Model: "EOS 5D/II", Subfolder: "Trips/Tokyo?"}
+
+	result, err := MustParse("{subfolder}/{model}/{camera}/{name}{ext}").Execute(values)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if expected := "Trips/Tokyo_/EOS 5D_II/EOS 5D_II/a_b.jpg"; result != expected {
+		t.Errorf("Execute() = %s, want %s", result, expected)
+	}
+
+	result, _ = MustParse("{camera}/{name}{ext}").Execute(&Values{Name: "x", Ext: ".jpg"})
+	if expected := "Unknown/x.jpg"; result != expected {
+		t.Errorf("Execute() = %s, want %s", result, expected)
+	}
+
+	// {date} 格式中的 ':' 等字符按片段替换，目录分隔符保留
+	date := &Values{Date: time.Date(2021, 3, 4, 9, 5, 7, 0, time.UTC), Name: "x", Ext: ".jpg"}
+	result, err = MustParse("{date:2006/15:04:05}/{date:20060102_15:04}{ext}").Execute(date)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if expected := "2021/09_05_07/20210304_09_05.jpg"; result != expected {
+		t.Errorf("Execute() = %s, want %s", result, expected)
+	}
+}
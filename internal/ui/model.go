@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"context"
+	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -8,8 +10,10 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/chiyiangel/media-organizer-v2/internal/config"
+	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
 	"github.com/chiyiangel/media-organizer-v2/internal/logger"
 	"github.com/chiyiangel/media-organizer-v2/internal/organizer"
+	"github.com/chiyiangel/media-organizer-v2/internal/pathtemplate"
 )
 
 // Screen 界面类型
@@ -26,9 +30,13 @@ const (
 type InputMode int
 
 const (
-	InputNone   InputMode = iota // 无输入
-	InputSource                  // 输入源目录
-	InputTarget                  // 输入目标目录
+	InputNone        InputMode = iota // 无输入
+	InputSource                       // 输入源目录
+	InputTarget                       // 输入目标目录
+	InputTemplate                     // 输入路径模板
+	InputRename                       // 输入重命名模式
+	InputPhotoTarget                  // 输入照片目标目录
+	InputVideoTarget                  // 输入视频目标目录
 )
 
 // Model Bubble Tea 主模型
@@ -50,10 +58,14 @@ type Model struct {
 	statistics   *organizer.Statistics
 	records      []organizer.ProcessRecord
 	allFiles     []*organizer.FileInfo
+	processor    *organizer.Processor
 
 	// 日志记录器
 	logger      *logger.Logger
 	logFilePath string
+	journal     *organizer.Journal
+	results     <-chan organizer.PipelineResult
+	journalPath string
 
 	// 窗口尺寸
 	width  int
@@ -62,15 +74,21 @@ type Model struct {
 	// 错误信息
 	err error
 
-	// 取消标志
-	cancelled bool
+	// 取消整理：取消后进行中的文件在数据块边界停止，随后显示汇总
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewModel 创建新模型
-func NewModel() Model {
+// cfg 为合并后的配置（默认值、配置文件和命令行），界面中只能修改其中的部分字段，
+// 其余字段（例如按类型的路径模板）按原样使用；为 nil 时使用默认配置
+func NewModel(cfg *config.Config) Model {
+	if cfg == nil {
+		cfg = config.NewDefaultConfig()
+	}
 	return Model{
 		currentScreen: ScreenConfig,
-		config:        config.NewDefaultConfig(),
+		config:        cfg,
 		inputMode:     InputNone,
 		statistics:    &organizer.Statistics{},
 		records:       make([]organizer.ProcessRecord, 0),
@@ -141,6 +159,11 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// 全局快捷键
 	switch msg.String() {
 	case "ctrl+c":
+		// 整理中第一次按下时取消并显示汇总，再按一次直接退出
+		if m.currentScreen == ScreenProgress && m.ctx != nil && m.ctx.Err() == nil {
+			m.cancelOrganizing()
+			return m, nil
+		}
 		if m.logger != nil {
 			m.logger.Close()
 		}
@@ -170,11 +193,10 @@ func (m Model) handleEscape() (tea.Model, tea.Cmd) {
 	case ScreenInput:
 		m.currentScreen = ScreenConfig
 		m.inputMode = InputNone
+		m.err = nil
 		return m, nil
 	case ScreenProgress:
-		m.cancelled = true
-		m.currentScreen = ScreenConfig
-		m.isOrganizing = false
+		m.cancelOrganizing()
 		return m, nil
 	case ScreenConfig, ScreenSummary:
 		if m.logger != nil {
@@ -209,8 +231,9 @@ func (m Model) handleFileScanComplete(msg FileScanCompleteMsg) (tea.Model, tea.C
 	m.currentScreen = ScreenProgress
 	m.isOrganizing = true
 
-	// 开始处理第一个文件
-	return m, m.processNextFileCmd(0)
+	// 启动并发处理，结果按文件顺序返回
+	m.results = m.processor.ProcessAll(m.ctx, msg.Files, m.config.Workers)
+	return m, m.processNextFileCmd()
 }
 
 // handleFileProcessed 处理文件处理完成
@@ -220,15 +243,9 @@ func (m Model) handleFileProcessed(msg FileProcessedMsg) (tea.Model, tea.Cmd) {
 
 	// 更新统计
 	m.statistics.ProcessedFiles++
-
-	switch msg.Record.Result {
-	case organizer.ResultSuccess:
-		// 成功计数已在ProcessedFiles中
-	case organizer.ResultSkipped:
-		m.statistics.SkippedCount++
-	case organizer.ResultFailed:
-		m.statistics.FailedCount++
-	}
+	m.statistics.AddDateSource(msg.Record.File.DateSource)
+	// 成功计数已在ProcessedFiles中
+	m.statistics.AddResult(msg.Record)
 
 	// 记录到日志
 	if m.logger != nil {
@@ -236,7 +253,7 @@ func (m Model) handleFileProcessed(msg FileProcessedMsg) (tea.Model, tea.Cmd) {
 	}
 
 	// 处理下一个文件
-	return m, m.processNextFileCmd(msg.FileIndex + 1)
+	return m, m.processNextFileCmd()
 }
 
 // handleOrganizeComplete 处理整理完成
@@ -246,8 +263,20 @@ func (m Model) handleOrganizeComplete(msg OrganizeCompleteMsg) (tea.Model, tea.C
 	m.isOrganizing = false
 	m.currentScreen = ScreenSummary
 
+	m.statistics.EndTime = time.Now()
+	m.statistics.Duration = m.statistics.EndTime.Sub(m.statistics.StartTime)
+	m.statistics.Cancelled = m.ctx.Err() != nil
+	m.statistics.HashTiers = m.processor.HashTiers()
+	m.cancel()
+
 	// 记录统计到日志
 	if m.logger != nil {
+		if m.journal != nil {
+			if err := m.journal.Close(); err != nil {
+				m.logger.LogError(i18n.Tf("silent.journal_failed", err.Error()))
+			}
+			m.journal = nil
+		}
 		m.logger.LogStatistics(m.statistics)
 		m.logger.Close()
 	}
@@ -263,7 +292,9 @@ func (m Model) startOrganizing() (tea.Model, tea.Cmd) {
 		StartTime: time.Now(),
 	}
 	m.records = make([]organizer.ProcessRecord, 0)
-	m.cancelled = false
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	// 整个运行共用一个处理器，保证重命名序号连续
+	m.processor = organizer.NewProcessor(m.config)
 
 	// 创建日志记录器
 	log, err := logger.NewLogger()
@@ -276,6 +307,21 @@ func (m Model) startOrganizing() (tea.Model, tea.Cmd) {
 	m.logger = log
 	m.logFilePath = log.GetPath()
 
+	// 创建操作记录，用于撤销本次运行
+	m.journal, m.journalPath = nil, ""
+	if !m.config.DryRun {
+		journal, err := organizer.NewJournal()
+		if err != nil {
+			m.err = err
+			m.isOrganizing = false
+			m.currentScreen = ScreenConfig
+			return m, nil
+		}
+		m.journal = journal
+		m.journalPath = journal.GetPath()
+		m.processor.SetJournal(journal)
+	}
+
 	// 启动整理任务
 	return m, m.organizeCmd()
 }
@@ -283,9 +329,27 @@ func (m Model) startOrganizing() (tea.Model, tea.Cmd) {
 // organizeCmd 整理命令 - 只扫描文件并启动处理
 func (m Model) organizeCmd() tea.Cmd {
 	return func() tea.Msg {
+		// 清理上次中断遗留的临时文件
+		if !m.config.DryRun {
+			removed, err := m.processor.CleanupTempFiles()
+			if err != nil {
+				m.logger.LogError(i18n.Tf("silent.temp_cleanup_failed", err.Error()))
+			}
+			if removed > 0 {
+				m.logger.LogInfo(i18n.Tf("silent.temp_cleaned", removed))
+			}
+		}
+
 		// 扫描文件
 		scanner := organizer.NewScanner(m.config.SourceDir)
-		files, err := scanner.Scan()
+		files, err := scanner.Scan(m.ctx)
+		if err == nil {
+			// 源目录中的重复文件只导入一份
+			_, err = m.processor.DedupeSource(m.ctx, files)
+		}
+		if m.ctx.Err() != nil {
+			return OrganizeCompleteMsg{Statistics: m.statistics, LogPath: m.logFilePath}
+		}
 		if err != nil {
 			return OrganizeErrorMsg{Err: err}
 		}
@@ -296,37 +360,32 @@ func (m Model) organizeCmd() tea.Cmd {
 	}
 }
 
-// processNextFileCmd 处理下一个文件
-func (m Model) processNextFileCmd(fileIndex int) tea.Cmd {
-	if fileIndex >= len(m.allFiles) || m.cancelled {
-		// 所有文件处理完成
-		m.statistics.EndTime = time.Now()
-		m.statistics.Duration = m.statistics.EndTime.Sub(m.statistics.StartTime)
-
-		return func() tea.Msg {
-			return OrganizeCompleteMsg{
-				Statistics: m.statistics,
-				LogPath:    m.logFilePath,
+// processNextFileCmd 等待下一个文件的处理结果，全部完成（或取消）后发送完成消息
+func (m Model) processNextFileCmd() tea.Cmd {
+	return func() tea.Msg {
+		for result := range m.results {
+			if result.Record == nil {
+				// 取消时尚未处理的文件
+				continue
+			}
+			return FileProcessedMsg{
+				Record:    result.Record,
+				FileIndex: result.Index,
+				Total:     len(m.allFiles),
 			}
 		}
-	}
-
-	return func() tea.Msg {
-		file := m.allFiles[fileIndex]
-		processor := organizer.NewProcessor(m.config)
 
-		record, _ := processor.Process(file)
-
-		// 记录到日志
-		if m.logger != nil {
-			m.logger.LogRecord(record)
+		return OrganizeCompleteMsg{
+			Statistics: m.statistics,
+			LogPath:    m.logFilePath,
 		}
+	}
+}
 
-		return FileProcessedMsg{
-			Record:    record,
-			FileIndex: fileIndex,
-			Total:     len(m.allFiles),
-		}
+// cancelOrganizing 取消整理，处理中的文件停止后显示汇总
+func (m Model) cancelOrganizing() {
+	if m.cancel != nil {
+		m.cancel()
 	}
 }
 
@@ -356,7 +415,28 @@ func (m Model) handleInputKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.config.SourceDir = m.inputValue
 		case InputTarget:
 			m.config.TargetDir = m.inputValue
+		case InputPhotoTarget:
+			m.config.PhotoTargetDir = m.inputValue
+		case InputVideoTarget:
+			m.config.VideoTargetDir = m.inputValue
+		case InputTemplate:
+			if m.inputValue != "" {
+				if _, err := pathtemplate.Parse(m.inputValue); err != nil {
+					m.err = err
+					return m, nil
+				}
+			}
+			m.config.PathTemplate = m.inputValue
+		case InputRename:
+			if m.inputValue != "" {
+				if _, err := pathtemplate.ParseName(m.inputValue); err != nil {
+					m.err = err
+					return m, nil
+				}
+			}
+			m.config.RenamePattern = m.inputValue
 		}
+		m.err = nil
 		m.currentScreen = ScreenConfig
 		m.inputMode = InputNone
 		return m, nil
@@ -388,11 +468,40 @@ func (m Model) handleConfigKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.inputMode = InputTarget
 		m.inputValue = m.config.TargetDir
 		return m, nil
+	case "p":
+		m.currentScreen = ScreenInput
+		m.inputMode = InputPhotoTarget
+		m.inputValue = m.config.PhotoTargetDir
+		return m, nil
+	case "v":
+		m.currentScreen = ScreenInput
+		m.inputMode = InputVideoTarget
+		m.inputValue = m.config.VideoTargetDir
+		return m, nil
+	case "t":
+		m.currentScreen = ScreenInput
+		m.inputMode = InputTemplate
+		m.inputValue = m.config.PathTemplate
+		return m, nil
+	case "n":
+		m.currentScreen = ScreenInput
+		m.inputMode = InputRename
+		m.inputValue = m.config.RenamePattern
+		return m, nil
 	case "f":
 		m.config.DuplicateDetection = config.DetectionFilename
 		return m, nil
 	case "m":
-		m.config.DuplicateDetection = config.DetectionMD5
+		m.config.DuplicateDetection = config.DetectionHash
+		return m, nil
+	case "l":
+		m.config.DuplicateDetection = config.DetectionLibrary
+		return m, nil
+	case "i":
+		m.config.DuplicateDetection = config.DetectionPerceptual
+		return m, nil
+	case "h":
+		m.config.KeepHigherResolution = !m.config.KeepHigherResolution
 		return m, nil
 	case "1":
 		m.config.DuplicateStrategy = config.StrategySkip
@@ -403,11 +512,29 @@ func (m Model) handleConfigKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "3":
 		m.config.DuplicateStrategy = config.StrategyRename
 		return m, nil
+	case "a":
+		// 旧版本按算法命名的策略切换为 hash，算法移到 HashAlgorithm
+		if m.config.DuplicateDetection.ContentHash() {
+			m.config.DuplicateDetection = config.DetectionHash
+		}
+		m.config.HashAlgorithm = nextHashAlgorithm(m.config.DigestAlgorithm())
+		return m, nil
+	case "x":
+		m.config.TransferMode = nextTransferMode(m.config.TransferMode)
+		return m, nil
+	case "y":
+		m.config.DryRun = !m.config.DryRun
+		return m, nil
 	case "enter":
 		if err := m.config.Validate(); err != nil {
 			m.err = err
 			return m, nil
 		}
+		if m.config.TargetDirFor(string(organizer.FileTypePhoto)) == "" ||
+			m.config.TargetDirFor(string(organizer.FileTypeVideo)) == "" {
+			m.err = fmt.Errorf("%s", i18n.T("error.target_not_set"))
+			return m, nil
+		}
 		return m.startOrganizing()
 	case "q":
 		if m.logger != nil {
@@ -418,11 +545,29 @@ func (m Model) handleConfigKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// nextTransferMode 切换到下一个传输方式
+func nextTransferMode(current config.TransferMode) config.TransferMode {
+	for i, mode := range config.TransferModes {
+		if mode == current {
+			return config.TransferModes[(i+1)%len(config.TransferModes)]
+		}
+	}
+	return config.TransferModes[0]
+}
+
+// nextHashAlgorithm 返回下一个内容摘要算法
+func nextHashAlgorithm(current string) string {
+	for i, algorithm := range config.HashAlgorithms {
+		if algorithm == current {
+			return config.HashAlgorithms[(i+1)%len(config.HashAlgorithms)]
+		}
+	}
+	return config.HashAlgorithms[0]
+}
+
 func (m Model) handleProgressKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if strings.ToLower(msg.String()) == "c" {
-		m.cancelled = true
-		m.isOrganizing = false
-		m.currentScreen = ScreenConfig
+		m.cancelOrganizing()
 		return m, nil
 	}
 	return m, nil
@@ -438,7 +583,11 @@ func (m Model) handleSummaryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.err = nil
 		return m, nil
 	case "o":
-		return m, openDirectory(m.config.TargetDir)
+		target := m.config.TargetDir
+		if target == "" {
+			target = m.config.TargetDirFor(string(organizer.FileTypePhoto))
+		}
+		return m, openDirectory(target)
 	case "q":
 		if m.logger != nil {
 			m.logger.Close()
@@ -7,6 +7,8 @@ import (
 
 	"github.com/chiyiangel/media-organizer-v2/internal/config"
 	"github.com/chiyiangel/media-organizer-v2/internal/i18n"
+	"github.com/chiyiangel/media-organizer-v2/internal/organizer"
+	"github.com/chiyiangel/media-organizer-v2/internal/pathtemplate"
 )
 
 // renderConfigScreen 渲染主配置界面
@@ -37,6 +39,62 @@ func (m Model) renderConfigScreen() string {
 	}
 	b.WriteString("\n")
 	b.WriteString(hintStyle.Render(i18n.T("config.edit_target_hint")))
+	b.WriteString("\n")
+
+	// 按类型的目标目录
+	for _, item := range []struct {
+		label string
+		dir   string
+	}{
+		{"config.photo_target_dir", m.config.PhotoTargetDir},
+		{"config.video_target_dir", m.config.VideoTargetDir},
+	} {
+		b.WriteString(labelStyle.Render(i18n.T(item.label)))
+		if item.dir == "" {
+			b.WriteString(hintStyle.Render(i18n.T("config.same_as_target")))
+		} else {
+			b.WriteString(textStyle.Render(item.dir))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(hintStyle.Render(i18n.T("config.edit_type_hint")))
+	b.WriteString("\n\n")
+
+	// 路径模板
+	b.WriteString(labelStyle.Render(i18n.T("config.path_template")))
+	if m.config.PathTemplate == "" {
+		b.WriteString(hintStyle.Render(pathtemplate.DefaultLayout))
+	} else {
+		b.WriteString(textStyle.Render(m.config.PathTemplate))
+	}
+	b.WriteString("\n")
+
+	// 按类型的路径模板（只能在配置文件或命令行中设置，设置后优先于上面的模板）
+	for _, item := range []struct {
+		label    string
+		template string
+	}{
+		{"config.photo_target_dir", m.config.PhotoPathTemplate},
+		{"config.video_target_dir", m.config.VideoPathTemplate},
+	} {
+		if item.template != "" {
+			b.WriteString(labelStyle.Render(i18n.T(item.label)))
+			b.WriteString(textStyle.Render(item.template))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString(hintStyle.Render(i18n.T("config.edit_template_hint")))
+	b.WriteString("\n\n")
+
+	// 重命名模式
+	b.WriteString(labelStyle.Render(i18n.T("config.rename_pattern")))
+	if m.config.RenamePattern == "" {
+		b.WriteString(hintStyle.Render(i18n.T("config.rename_disabled")))
+	} else {
+		b.WriteString(textStyle.Render(m.config.RenamePattern))
+	}
+	b.WriteString("\n")
+	b.WriteString(hintStyle.Render(i18n.T("config.edit_rename_hint")))
 	b.WriteString("\n\n")
 
 	// 整理策略
@@ -46,13 +104,32 @@ func (m Model) renderConfigScreen() string {
 	// 同文件识别
 	detectionF := " "
 	detectionM := " "
-	if m.config.DuplicateDetection == config.DetectionFilename {
+	detectionL := " "
+	detectionI := " "
+	switch m.config.DuplicateDetection {
+	case config.DetectionFilename:
 		detectionF = "●"
-	} else {
+	case config.DetectionLibrary:
+		detectionL = "●"
+	case config.DetectionPerceptual:
+		detectionI = "●"
+	default:
 		detectionM = "●"
 	}
 
-	b.WriteString(textStyle.Render(i18n.Tf("config.file_detection", detectionF, detectionM)))
+	b.WriteString(textStyle.Render(i18n.Tf("config.file_detection", detectionF, detectionM, detectionL)))
+	b.WriteString("\n")
+
+	// 哈希算法（所有识别策略以及校验、操作日志共用）
+	b.WriteString(textStyle.Render(i18n.Tf("config.hash_algorithm", m.config.DigestAlgorithm())))
+	b.WriteString("\n")
+
+	// 相似照片
+	keepHigher := " "
+	if m.config.KeepHigherResolution {
+		keepHigher = "●"
+	}
+	b.WriteString(textStyle.Render(i18n.Tf("config.similar_photos", detectionI, keepHigher)))
 	b.WriteString("\n")
 
 	// 重复处理
@@ -69,6 +146,22 @@ func (m Model) renderConfigScreen() string {
 	}
 
 	b.WriteString(textStyle.Render(i18n.Tf("config.duplicate_handling", strategy1, strategy2, strategy3)))
+	b.WriteString("\n")
+
+	// 传输方式
+	transferMode := m.config.TransferMode
+	if transferMode == "" {
+		transferMode = config.TransferCopy
+	}
+	b.WriteString(textStyle.Render(i18n.Tf("config.transfer_mode", i18n.T("transfer."+string(transferMode)))))
+	b.WriteString("\n")
+
+	// 演练模式
+	dryRun := i18n.T("config.off")
+	if m.config.DryRun {
+		dryRun = i18n.T("config.on")
+	}
+	b.WriteString(textStyle.Render(i18n.Tf("config.dry_run", dryRun)))
 	b.WriteString("\n\n")
 
 	// 分割线 - 调整宽度以匹配边框
@@ -108,11 +201,19 @@ func (m Model) renderInputScreen() string {
 
 	// 标题
 	title := i18n.T("input.title")
+	prompt := i18n.T("input.prompt")
+	switch m.inputMode {
+	case InputTemplate:
+		title = i18n.T("input.template_title")
+		prompt = i18n.T("input.template_prompt")
+	case InputRename:
+		title = i18n.T("input.rename_title")
+		prompt = i18n.T("input.rename_prompt")
+	}
 	b.WriteString(titleStyle.Width(m.width).Render(title))
 	b.WriteString("\n\n")
 
 	// 提示
-	prompt := i18n.T("input.prompt")
 	b.WriteString(labelStyle.Render(prompt))
 	b.WriteString("\n")
 
@@ -121,6 +222,12 @@ func (m Model) renderInputScreen() string {
 	b.WriteString(textStyle.Render(inputBox))
 	b.WriteString("\n\n")
 
+	// 错误信息
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(i18n.T("error.prefix") + m.err.Error()))
+		b.WriteString("\n\n")
+	}
+
 	// 提示
 	b.WriteString(hintStyle.Render(i18n.T("input.confirm_hint")))
 	b.WriteString("\n")
@@ -187,7 +294,11 @@ func (m Model) renderProgressScreen() string {
 	}
 
 	// 提示
-	b.WriteString(hintStyle.Render(i18n.T("progress.cancel_hint")))
+	if m.ctx != nil && m.ctx.Err() != nil {
+		b.WriteString(warningStyle.Render(i18n.T("progress.cancelling")))
+	} else {
+		b.WriteString(hintStyle.Render(i18n.T("progress.cancel_hint")))
+	}
 	b.WriteString("\n")
 
 	// 确保容器宽度合理
@@ -204,7 +315,13 @@ func (m Model) renderSummaryScreen() string {
 	var b strings.Builder
 
 	// 标题
-	b.WriteString(titleStyle.Width(m.width).Render(i18n.T("summary.title")))
+	title := i18n.T("summary.title")
+	if m.statistics.Cancelled {
+		title = i18n.T("summary.cancelled_title")
+	} else if m.config.DryRun {
+		title = i18n.T("summary.dry_run_title")
+	}
+	b.WriteString(titleStyle.Width(m.width).Render(title))
 	b.WriteString("\n\n")
 
 	// 汇总报告标题
@@ -228,8 +345,53 @@ func (m Model) renderSummaryScreen() string {
 	b.WriteString(successStyle.Render(i18n.Tf("summary.success", successCount) + "\n"))
 	b.WriteString(warningStyle.Render(i18n.Tf("summary.skipped", m.statistics.SkippedCount) + "\n"))
 	b.WriteString(errorStyle.Render(i18n.Tf("summary.failed", m.statistics.FailedCount) + "\n"))
+	if m.statistics.Cancelled {
+		b.WriteString(warningStyle.Render(i18n.Tf("summary.unprocessed",
+			m.statistics.TotalFiles-m.statistics.ProcessedFiles) + "\n"))
+	}
+	if tiers := m.statistics.HashTiers; tiers.Total() > 0 {
+		b.WriteString(textStyle.Render(i18n.Tf("summary.hash_tiers", tiers.Size, tiers.Partial, tiers.Full) + "\n"))
+	}
 	b.WriteString("\n")
 
+	// 演练计划
+	if m.config.DryRun {
+		b.WriteString(m.renderPlan())
+	}
+
+	// 目标目录
+	b.WriteString(labelStyle.Render(i18n.T("summary.destinations")))
+	b.WriteString("\n")
+	b.WriteString(textStyle.Render(i18n.Tf("summary.destination_item", i18n.T("file.photo"),
+		m.statistics.WrittenCounts[organizer.FileTypePhoto], m.config.TargetDirFor(string(organizer.FileTypePhoto))) + "\n"))
+	b.WriteString(textStyle.Render(i18n.Tf("summary.destination_item", i18n.T("file.video"),
+		m.statistics.WrittenCounts[organizer.FileTypeVideo], m.config.TargetDirFor(string(organizer.FileTypeVideo))) + "\n"))
+	b.WriteString("\n")
+
+	// 日期来源
+	if len(m.statistics.DateSourceCounts) > 0 {
+		b.WriteString(labelStyle.Render(i18n.T("summary.date_sources")))
+		b.WriteString("\n")
+		for _, source := range organizer.DateSources {
+			count := m.statistics.DateSourceCounts[source]
+			if count == 0 {
+				continue
+			}
+			line := i18n.Tf("summary.date_source_item",
+				i18n.T("date_source."+string(source)), count,
+				fmt.Sprintf("%.1f", m.statistics.DateSourcePercent(source)))
+			if source == organizer.DateSourceMtime {
+				b.WriteString(warningStyle.Render(line + "\n"))
+			} else {
+				b.WriteString(textStyle.Render(line + "\n"))
+			}
+		}
+		if m.statistics.DateSourceCounts[organizer.DateSourceMtime] > 0 {
+			b.WriteString(warningStyle.Render(i18n.T("summary.mtime_warning") + "\n"))
+		}
+		b.WriteString("\n")
+	}
+
 	// 性能数据
 	b.WriteString(labelStyle.Render(i18n.T("summary.performance")))
 	b.WriteString("\n")
@@ -240,7 +402,13 @@ func (m Model) renderSummaryScreen() string {
 	// 详细日志
 	b.WriteString(labelStyle.Render(i18n.T("summary.log_file")))
 	b.WriteString(textStyle.Render(m.logFilePath))
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if m.journalPath != "" {
+		b.WriteString(labelStyle.Render(i18n.T("summary.journal_file")))
+		b.WriteString(textStyle.Render(m.journalPath))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	// 分割线 - 统一宽度计算
 	dividerWidth := m.width - 8
@@ -266,3 +434,35 @@ func (m Model) renderSummaryScreen() string {
 
 	return borderStyle.Width(containerWidth).Render(b.String())
 }
+
+// maxPlanLines 汇总界面显示的计划条目上限
+const maxPlanLines = 10
+
+// renderPlan 渲染演练计划（完整计划见日志）
+func (m Model) renderPlan() string {
+	var b strings.Builder
+
+	b.WriteString(labelStyle.Render(i18n.Tf("summary.plan", len(m.records))))
+	b.WriteString("\n")
+	for i, record := range m.records {
+		if i == maxPlanLines {
+			b.WriteString(hintStyle.Render(i18n.Tf("summary.plan_more", len(m.records)-maxPlanLines) + "\n"))
+			break
+		}
+
+		switch record.Result {
+		case organizer.ResultFailed:
+			b.WriteString(errorStyle.Render(i18n.Tf("summary.plan_item",
+				i18n.T("action.failed"), record.File.Name, record.Message) + "\n"))
+		case organizer.ResultSkipped:
+			b.WriteString(warningStyle.Render(i18n.Tf("summary.plan_item",
+				i18n.T("action.skip"), record.File.Name, record.Message) + "\n"))
+		default:
+			b.WriteString(textStyle.Render(i18n.Tf("summary.plan_item",
+				i18n.T("action."+string(record.Action)), record.File.Name, record.File.TargetPath) + "\n"))
+		}
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}